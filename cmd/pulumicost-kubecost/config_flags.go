@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// configFlags are the command-line configuration layers shared by the plugin
+// and the validate-config subcommand.
+type configFlags struct {
+	path      string
+	profile   string
+	overrides stringList
+}
+
+func registerConfigFlags(fs *flag.FlagSet) *configFlags {
+	f := &configFlags{}
+	fs.StringVar(&f.path, "config", os.Getenv("KUBECOST_CONFIG"), "Path to the YAML config file (default $KUBECOST_CONFIG)")
+	fs.StringVar(&f.profile, "profile", "", "Config profile to use (overrides $KUBECOST_PROFILE)")
+	fs.Var(&f.overrides, "set", "Override a config key, e.g. -set timeout=30s (repeatable)")
+	return f
+}
+
+func (f *configFlags) loadOptions() kubecost.LoadOptions {
+	return kubecost.LoadOptions{Path: f.path, Profile: f.profile, Overrides: f.overrides}
+}
@@ -3,16 +3,22 @@ package main
 import (
 	"context"
 	"flag"
-	"log"
+	"log/slog"
 	"net"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/metrics"
 	"github.com/rshade/pulumicost-plugin-kubecost/internal/server"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/tracing"
 	"github.com/rshade/pulumicost-plugin-kubecost/pkg/version"
 	// TODO: Add when pulumicost-spec is available
 	// pbc "github.com/yourorg/pulumicost-spec/sdk/go/proto"
@@ -22,6 +28,9 @@ func main() {
 	// Parse command line flags
 	showVersion := flag.Bool("version", false, "Show version information")
 	showVersionFull := flag.Bool("version-full", false, "Show detailed version information")
+	configFlags := registerConfigFlags(flag.CommandLine)
+	reloadInterval := flag.Duration("config-reload-interval", defaultReloadInterval,
+		"How often to check the config file for changes, 0 disables (SIGHUP always reloads)")
 	flag.Parse()
 
 	// Handle version flags
@@ -34,45 +43,166 @@ func main() {
 		os.Exit(0)
 	}
 
-	cfg, err := kubecost.LoadConfigFromEnvOrFile(os.Getenv("KUBECOST_CONFIG"))
+	switch flag.Arg(0) {
+	case "validate-config":
+		os.Exit(runValidateConfig(flag.Args()[1:], os.Stdout, os.Stderr))
+	case "manifest":
+		os.Exit(runManifest(flag.Args()[1:], os.Stdout, os.Stderr))
+	}
+
+	redactor := logging.NewRedactor()
+	if err := setupLogging(kubecost.Config{}, redactor); err != nil {
+		fatal("logging", err)
+	}
+
+	cfg, err := kubecost.LoadValidatedConfig(configFlags.loadOptions())
 	if err != nil {
-		log.Fatalf("config: %v", err)
+		fatal("config", err)
+	}
+	if err = setupLogging(cfg, redactor); err != nil {
+		fatal("logging", err)
 	}
 
-	cli, err := kubecost.NewClient(cubectx(context.Background()), cfg)
+	cli, err := kubecost.NewClient(context.Background(), cfg)
 	if err != nil {
-		log.Fatalf("client: %v", err)
+		fatal("client", err)
 	}
 
-	log.Printf("pulumicost-kubecost starting, %s", version.String())
+	startAttrs := []any{"version", version.String()}
+	if cfg.Profile != "" {
+		startAttrs = append(startAttrs, "profile", cfg.Profile)
+	}
+	if cfg.ProxyURL != "" {
+		startAttrs = append(startAttrs, "proxy", kubecost.RedactURL(cfg.ProxyURL))
+	}
+	if cfg.TracingExporter != tracing.ExporterNone {
+		startAttrs = append(startAttrs, "tracing", cfg.TracingExporter)
+	}
+	slog.Info("pulumicost-kubecost starting", startAttrs...)
 
-	// Pulumi-style plugins often use stdin/stdout. For simplicity here, use a TCP loopback.
-	// Your plugin host can launch and connect to this ephemeral port; or adapt to stdio transport.
-	lis, err := net.Listen("tcp", "127.0.0.1:50051")
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:       cfg.TracingExporter,
+		Endpoint:       cfg.OTLPEndpoint,
+		Insecure:       cfg.OTLPInsecure,
+		ServiceVersion: version.GetVersionInfo().Version,
+	})
 	if err != nil {
-		log.Fatalf("listen: %v", err)
+		fatal("tracing", err)
+	}
+
+	// Pulumi-style plugins often use stdin/stdout. For simplicity here, use a TCP loopback
+	// by default. Your plugin host can launch and connect to this port; or adapt to stdio
+	// transport. Shared sidecars listen more widely with TLS and an auth token.
+	creds, security, err := serverCredentials(cfg)
+	if err != nil {
+		fatal("server tls", err)
+	}
+	lis, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		fatal("listen", err)
+	}
+	if security == "plaintext" && !isLoopback(lis.Addr()) {
+		slog.Warn("serving plaintext gRPC beyond loopback; set serverCertFile to enable TLS",
+			"address", lis.Addr().String())
+	}
+
+	if cfg.MetricsAddress != "" {
+		metricsLis, listenErr := net.Listen("tcp", cfg.MetricsAddress)
+		if listenErr != nil {
+			fatal("metrics listen", listenErr)
+		}
+		slog.Info("serving metrics", "address", metricsLis.Addr().String(), "path", metrics.Path)
+		go func() {
+			if serveErr := metrics.Serve(context.Background(), metricsLis); serveErr != nil {
+				slog.Error("metrics listener stopped", "error", serveErr)
+			}
+		}()
 	}
 
-	grpcServer := grpc.NewServer(grpc.Creds(insecure.NewCredentials()))
-	_ = server.NewKubecostServer(cli)
+	grpcServer := grpc.NewServer(append(server.ServerOptions(server.InterceptorOptions{
+		Timeout:       cfg.RPCTimeout,
+		StreamTimeout: cfg.StreamRPCTimeout,
+		AuthToken:     cfg.ServerAuthToken,
+	}), grpc.Creds(creds))...)
+	kubecostServer := server.NewKubecostServer(cli)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	reloader := server.NewReloader(kubecostServer, configFlags.loadOptions(), *reloadInterval)
+	reloader.OnReload(func(cfg kubecost.Config) {
+		if logErr := setupLogging(cfg, redactor); logErr != nil {
+			slog.Error("applying reloaded logging settings", "error", logErr)
+		}
+	})
+	go reloader.Run(context.Background(), hup)
 	// TODO: Uncomment when pulumicost-spec protobuf definitions are available
 	// kubecostServer.RegisterService(grpcServer)
 
-	log.Printf("listening on %s", lis.Addr().String())
+	// Stop gracefully on SIGINT/SIGTERM so buffered spans are flushed
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-stop
+		slog.Info("shutting down", "signal", sig.String())
+		grpcServer.GracefulStop()
+	}()
+
+	slog.Info("listening", "address", lis.Addr().String(), "security", security,
+		"auth_token", cfg.ServerAuthToken != "")
 	if serveErr := grpcServer.Serve(lis); serveErr != nil {
-		log.Fatalf("serve: %v", serveErr)
+		fatal("serve", serveErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err = shutdownTracing(ctx); err != nil {
+		slog.Error("flushing traces", "error", err)
 	}
 }
 
-const defaultTimeoutSeconds = 30
+// serverCredentials returns the gRPC server's transport credentials for cfg and
+// describes them as plaintext, tls or mtls.
+func serverCredentials(cfg kubecost.Config) (credentials.TransportCredentials, string, error) {
+	tlsCfg, err := server.TLSConfig(cfg)
+	switch {
+	case err != nil:
+		return nil, "", err
+	case tlsCfg == nil:
+		return insecure.NewCredentials(), "plaintext", nil
+	case cfg.ServerClientCAFile != "":
+		return credentials.NewTLS(tlsCfg), "mtls", nil
+	}
+	return credentials.NewTLS(tlsCfg), "tls", nil
+}
 
-func cubectx(ctx context.Context) context.Context {
-	t := defaultTimeoutSeconds * time.Second
-	if d := os.Getenv("KUBECOST_TIMEOUT"); d != "" {
-		if parsed, err := time.ParseDuration(d); err == nil {
-			t = parsed
-		}
+// isLoopback reports whether addr is a loopback TCP address.
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// setupLogging installs the default logger for cfg's level and format, masking
+// cfg's credentials in every record.
+func setupLogging(cfg kubecost.Config, r *logging.Redactor) error {
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, level, r, kubecost.SecretKeys()...)
+	if err != nil {
+		return err
 	}
-	c, _ := context.WithTimeout(ctx, t) //nolint:govet // cancel not needed for this use case
-	return c
+	r.SetSecrets(cfg.Secrets()...)
+	slog.SetDefault(logger)
+	return nil
 }
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+const defaultReloadInterval = 5 * time.Second
+
+// shutdownTimeout bounds flushing buffered spans on exit.
+const shutdownTimeout = 5 * time.Second
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/manifest"
+)
+
+// runManifest implements `pulumicost-kubecost manifest`: it prints the plugin
+// manifest, or the config JSON Schema with -schema, or writes both files into
+// a directory with -write.
+func runManifest(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("manifest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schema := fs.Bool("schema", false, "Print the JSON Schema of the YAML config instead of the manifest")
+	dir := fs.String("write", "", "Write "+manifest.ManifestFile+" and "+manifest.SchemaFile+" into this directory")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *dir != "" {
+		if err := manifest.Write(*dir); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		return exitOK
+	}
+
+	generate := manifest.Manifest
+	if *schema {
+		generate = manifest.Schema
+	}
+	b, err := generate()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	_, _ = stdout.Write(b)
+	return exitOK
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+// Exit codes of the validate-config subcommand.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+// runValidateConfig implements `pulumicost-kubecost validate-config`: it loads
+// the configuration the plugin would start with and prints every problem
+// found, exiting non-zero so CI jobs fail on a bad config.
+func runValidateConfig(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	flags := registerConfigFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	cfg, err := kubecost.LoadValidatedConfig(flags.loadOptions())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+
+	source := "environment"
+	if flags.path != "" {
+		source = flags.path + " and environment"
+	}
+	if cfg.Profile != "" {
+		source += ", profile " + cfg.Profile
+	}
+	fmt.Fprintf(stdout, "configuration OK (%s)\n", source)
+	return exitOK
+}
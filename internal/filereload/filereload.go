@@ -0,0 +1,92 @@
+// Package filereload keeps values built from files, such as TLS configurations
+// built from certificate files, current as the files change on disk, so rotated
+// certificates are used without restarting the plugin.
+package filereload
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CheckInterval bounds how often the files are stat'ed for changes.
+const CheckInterval = time.Second
+
+// Value holds a value built from files and rebuilds it when the newest of their
+// modification times advances. A rebuild that fails, for example because a
+// rotation is only half written, keeps the previous value.
+type Value[T any] struct {
+	files   []string
+	build   func() (T, error)
+	retired func(T)
+
+	mu      sync.Mutex
+	current T
+	stamp   time.Time
+	checked time.Time
+}
+
+// New builds the initial value from files. retired, when not nil, is called
+// with each value a rebuild replaces, e.g. to close its idle connections.
+func New[T any](files []string, build func() (T, error), retired func(T)) (*Value[T], error) {
+	stamp, err := LatestModTime(files)
+	if err != nil {
+		return nil, err
+	}
+	current, err := build()
+	if err != nil {
+		return nil, err
+	}
+	return &Value[T]{files: files, build: build, retired: retired, current: current, stamp: stamp}, nil
+}
+
+// Get returns the current value, rebuilding it first if the files changed since
+// it was built. The files are checked at most once per CheckInterval.
+func (v *Value[T]) Get() T {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(v.checked) < CheckInterval {
+		return v.current
+	}
+	v.checked = now
+
+	stamp, err := LatestModTime(v.files)
+	if err != nil || !stamp.After(v.stamp) {
+		return v.current
+	}
+	next, err := v.build()
+	if err != nil {
+		return v.current
+	}
+	if v.retired != nil {
+		v.retired(v.current)
+	}
+	v.current = next
+	v.stamp = stamp
+	return v.current
+}
+
+// Invalidate makes the next Get check the files regardless of CheckInterval.
+func (v *Value[T]) Invalidate() {
+	v.mu.Lock()
+	v.checked = time.Time{}
+	v.mu.Unlock()
+}
+
+// LatestModTime returns the newest modification time among files.
+func LatestModTime(files []string) (time.Time, error) {
+	var latest time.Time
+	for _, p := range files {
+		info, err := os.Stat(p)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("stat %s: %w", p, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
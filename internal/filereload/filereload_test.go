@@ -0,0 +1,70 @@
+package filereload //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// touch writes data to path with a modification time offset from now.
+func touch(t *testing.T, path, data string, offset time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+	at := time.Now().Add(offset)
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+}
+
+func TestValueReloadsChangedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "value")
+	touch(t, path, "one", -time.Minute)
+	read := func() (string, error) {
+		b, err := os.ReadFile(path)
+		if strings.TrimSpace(string(b)) == "" {
+			return "", errors.New("empty")
+		}
+		return string(b), err
+	}
+	var retired []string
+	v, err := New([]string{path}, read, func(old string) { retired = append(retired, old) })
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Checked at most once per interval
+	touch(t, path, "two", 0)
+	if got := v.Get(); got != "two" {
+		t.Errorf("Expected the first Get to see the change, got %q", got)
+	}
+	touch(t, path, "three", time.Minute)
+	if got := v.Get(); got != "two" {
+		t.Errorf("Expected no check within the interval, got %q", got)
+	}
+	v.Invalidate()
+	if got := v.Get(); got != "three" {
+		t.Errorf("Expected Invalidate to force a check, got %q", got)
+	}
+
+	// A failed rebuild keeps the previous value
+	touch(t, path, " ", 2*time.Minute)
+	v.Invalidate()
+	if got := v.Get(); got != "three" {
+		t.Errorf("Expected the previous value after a failed rebuild, got %q", got)
+	}
+	if strings.Join(retired, ",") != "one,two" {
+		t.Errorf("Expected the replaced values to be retired, got %v", retired)
+	}
+}
+
+func TestNewMissingFile(t *testing.T) {
+	_, err := New([]string{filepath.Join(t.TempDir(), "missing")}, func() (int, error) { return 0, nil }, nil)
+	if err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
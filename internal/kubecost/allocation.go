@@ -1,13 +1,11 @@
 package kubecost
 
 import (
+	"cmp"
 	"context"
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 )
@@ -27,31 +25,41 @@ type DetailedAllocationResponse struct {
 
 // AllocationEntry represents a single allocation entry from Kubecost.
 type AllocationEntry struct {
-	Name              string                 `json:"name"`
-	Properties        AllocationProperties   `json:"properties"`
-	Window            AllocationWindow       `json:"window"`
-	Start             string                 `json:"start"`
-	End               string                 `json:"end"`
-	Minutes           float64                `json:"minutes"`
-	CPUCores          float64                `json:"cpuCores"`
-	CPUCoreHours      float64                `json:"cpuCoreHours"`
-	CPUCost           float64                `json:"cpuCost"`
-	CPUEfficiency     float64                `json:"cpuEfficiency"`
-	GPUCount          float64                `json:"gpuCount"`
-	GPUHours          float64                `json:"gpuHours"`
-	GPUCost           float64                `json:"gpuCost"`
-	NetworkCost       float64                `json:"networkCost"`
-	LoadBalancerCost  float64                `json:"loadBalancerCost"`
-	PVCost            float64                `json:"pvCost"`
-	RAMBytes          float64                `json:"ramBytes"`
-	RAMByteHours      float64                `json:"ramByteHours"`
-	RAMCost           float64                `json:"ramCost"`
-	RAMEfficiency     float64                `json:"ramEfficiency"`
-	SharedCost        float64                `json:"sharedCost"`
-	ExternalCost      float64                `json:"externalCost"`
-	TotalCost         float64                `json:"totalCost"`
-	TotalEfficiency   float64                `json:"totalEfficiency"`
-	RawAllocationOnly map[string]interface{} `json:"rawAllocationOnly,omitempty"`
+	Name              string                  `json:"name"`
+	Properties        AllocationProperties    `json:"properties"`
+	Window            AllocationWindow        `json:"window"`
+	Start             string                  `json:"start"`
+	End               string                  `json:"end"`
+	Minutes           float64                 `json:"minutes"`
+	CPUCores          float64                 `json:"cpuCores"`
+	CPUCoreHours      float64                 `json:"cpuCoreHours"`
+	CPUCost           float64                 `json:"cpuCost"`
+	CPUEfficiency     float64                 `json:"cpuEfficiency"`
+	GPUCount          float64                 `json:"gpuCount"`
+	GPUHours          float64                 `json:"gpuHours"`
+	GPUCost           float64                 `json:"gpuCost"`
+	NetworkCost       float64                 `json:"networkCost"`
+	LoadBalancerCost  float64                 `json:"loadBalancerCost"`
+	PVCost            float64                 `json:"pvCost"`
+	PVs               map[string]PVAllocation `json:"pvs,omitempty"`
+	RAMBytes          float64                 `json:"ramBytes"`
+	RAMByteHours      float64                 `json:"ramByteHours"`
+	RAMCost           float64                 `json:"ramCost"`
+	RAMEfficiency     float64                 `json:"ramEfficiency"`
+	SharedCost        float64                 `json:"sharedCost"`
+	ExternalCost      float64                 `json:"externalCost"`
+	TotalCost         float64                 `json:"totalCost"`
+	TotalEfficiency   float64                 `json:"totalEfficiency"`
+	RawAllocationOnly map[string]interface{}  `json:"rawAllocationOnly,omitempty"`
+}
+
+// PVAllocation is an allocation's share of one persistent volume. Kubecost keys
+// them by "cluster=<cluster>:name=<pv>".
+type PVAllocation struct {
+	ByteHours  float64 `json:"byteHours"`
+	Cost       float64 `json:"cost"`
+	ProviderID string  `json:"providerID,omitempty"`
+	Adjustment float64 `json:"adjustment,omitempty"`
 }
 
 // AllocationProperties contains metadata about the allocation.
@@ -80,17 +88,20 @@ func (c *Client) BuildAllocationURL(q AllocationQuery) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("invalid base URL: %w", err)
 	}
-	u.Path = "/model/allocation"
+	u.Path = allocationPath
 
 	params := url.Values{}
 	params.Set("window", q.Window)
 
 	// Build filter string from map
-	if len(q.Filter) > 0 {
-		var filters []string
-		for k, v := range q.Filter {
-			filters = append(filters, fmt.Sprintf(`%s:"%s"`, k, v))
-		}
+	var filters []string
+	for k, v := range q.Filter {
+		filters = append(filters, fmt.Sprintf(`%s:"%s"`, k, v))
+	}
+	if len(q.Namespaces) > 0 {
+		filters = append(filters, filterClause("namespace", q.Namespaces...))
+	}
+	if len(filters) > 0 {
 		params.Set("filter", strings.Join(filters, "+"))
 	}
 
@@ -110,96 +121,176 @@ func (c *Client) BuildAllocationURL(q AllocationQuery) (string, error) {
 
 // GetDetailedAllocation retrieves detailed allocation data from Kubecost.
 func (c *Client) GetDetailedAllocation(ctx context.Context, q AllocationQuery) (*DetailedAllocationResponse, error) {
-	url, err := c.BuildAllocationURL(q)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var result DetailedAllocationResponse
+	env, err := c.streamAllocation(ctx, q, func(window int, name string, entry AllocationEntry) error {
+		for len(result.Data) <= window {
+			result.Data = append(result.Data, map[string]AllocationEntry{})
+		}
+		result.Data[window][name] = entry
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	for len(result.Data) < env.Windows {
+		result.Data = append(result.Data, map[string]AllocationEntry{})
 	}
+	result.Code = env.Code
+	result.Status = env.Status
+	result.Message = env.Message
 
-	if c.cfg.APIToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.cfg.APIToken)
-	}
-	req.Header.Set("Accept", "application/json")
+	return &result, nil
+}
+
+// ConvertToSimpleResponse converts detailed allocation to the simple response format.
+func ConvertToSimpleResponse(detailed *DetailedAllocationResponse) AllocationResponse {
+	var items []AllocationPoint
 
-	// Configure HTTP client with TLS settings
-	if c.http == nil {
-		c.http = &http.Client{
-			Timeout: c.cfg.Timeout,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: c.cfg.TLSSkipVerify, //nolint:gosec // Configurable for dev environments
-				},
-			},
+	for _, dayData := range detailed.Data {
+		for _, entry := range dayData {
+			items = append(items, entry.ToPoint())
 		}
 	}
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+	return AllocationResponse{Items: items}
+}
+
+// ToPoint maps a detailed allocation entry to a simple allocation point.
+func (entry AllocationEntry) ToPoint() AllocationPoint {
+	// Parse the window times
+	start := entry.Start
+	end := entry.End
+	if start == "" && entry.Window.Start != "" {
+		start = entry.Window.Start
+	}
+	if end == "" && entry.Window.End != "" {
+		end = entry.Window.End
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= httpClientErrorStatus {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("kubecost API error: status=%d, body=%s", resp.StatusCode, string(body))
+	return AllocationPoint{
+		Start:       start,
+		End:         end,
+		Cost:        entry.TotalCost,
+		CPUCost:     entry.CPUCost,
+		RAMCost:     entry.RAMCost,
+		GPUCost:     entry.GPUCost,
+		PVCCost:     entry.PVCost,
+		PVs:         entry.pvCosts(),
+		NetworkCost: entry.NetworkCost,
 	}
+}
 
-	var result DetailedAllocationResponse
-	if decodeErr := json.NewDecoder(resp.Body).Decode(&result); decodeErr != nil {
-		return nil, fmt.Errorf("decoding response: %w", decodeErr)
+// pvCosts returns the entry's per-volume costs ordered by cluster and volume.
+func (entry AllocationEntry) pvCosts() []PVCost {
+	if len(entry.PVs) == 0 {
+		return nil
+	}
+	out := make([]PVCost, 0, len(entry.PVs))
+	for key, pv := range entry.PVs {
+		cluster, name := parsePVKey(key)
+		out = append(out, PVCost{
+			Cluster:  cluster,
+			Name:     name,
+			GiBHours: pv.ByteHours / bytesPerGiB,
+			Cost:     pv.Cost + pv.Adjustment,
+		})
+	}
+	slices.SortFunc(out, func(a, b PVCost) int {
+		return cmp.Or(strings.Compare(a.Cluster, b.Cluster), strings.Compare(a.Name, b.Name))
+	})
+	return out
+}
+
+// parsePVKey splits a "cluster=<cluster>:name=<pv>" key. Keys in another
+// format are returned whole as the volume name.
+func parsePVKey(key string) (cluster, name string) {
+	c, n, ok := strings.Cut(key, ":name=")
+	if !ok || !strings.HasPrefix(c, "cluster=") {
+		return "", key
 	}
+	return strings.TrimPrefix(c, "cluster="), n
+}
 
-	if result.Code != httpSuccessStatus {
-		return nil, fmt.Errorf("kubecost API returned error code %d: %s", result.Code, result.Message)
+// EnhancedAllocation method that uses detailed allocation API to retrieve allocation data.
+// Windows longer than Config.ChunkWindow are split into chunks fetched concurrently,
+// which share one Config.Timeout.
+func (c *Client) EnhancedAllocation(ctx context.Context, q AllocationQuery) (AllocationResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	if chunks := c.planChunks(q); len(chunks) > 1 {
+		return c.chunkedAllocation(ctx, q, chunks)
 	}
 
-	return &result, nil
+	items, err := c.allocationPoints(ctx, q)
+	if err != nil {
+		return AllocationResponse{}, err
+	}
+
+	return AllocationResponse{Items: items}, nil
 }
 
-// ConvertToSimpleResponse converts detailed allocation to the simple response format.
-func ConvertToSimpleResponse(detailed *DetailedAllocationResponse) AllocationResponse {
-	var items []AllocationPoint
+// StreamAllocationEntries streams allocation entries for q to visit as they are
+// decoded. Chunked windows are fetched one chunk at a time, in order, so memory
+// stays bounded regardless of the window length. visit may block, e.g. on gRPC
+// flow control, so instead of one Config.Timeout for the whole walk, each chunk
+// is bounded by the deadline of ctx or, without one, by its own Config.Timeout;
+// see streamTimeout. The window index passed to visit is relative to the chunk
+// being decoded.
+//
+// Config.ChunkConcurrency and Config.ChunkFailurePolicy apply only to
+// EnhancedAllocation: chunks are streamed one at a time, and since entries of a
+// failing chunk may already have been visited, the first failed chunk fails the
+// stream.
+func (c *Client) StreamAllocationEntries(ctx context.Context, q AllocationQuery, visit AllocationVisitor) error {
+	checked := func(window int, name string, entry AllocationEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return visit(window, name, entry)
+	}
 
-	for _, dayData := range detailed.Data {
-		for _, entry := range dayData {
-			// Parse the window times
-			start := entry.Start
-			end := entry.End
-			if start == "" && entry.Window.Start != "" {
-				start = entry.Window.Start
-			}
-			if end == "" && entry.Window.End != "" {
-				end = entry.Window.End
-			}
-
-			items = append(items, AllocationPoint{
-				Start:       start,
-				End:         end,
-				Cost:        entry.TotalCost,
-				CPUCost:     entry.CPUCost,
-				RAMCost:     entry.RAMCost,
-				GPUCost:     entry.GPUCost,
-				PVCCost:     entry.PVCost,
-				NetworkCost: entry.NetworkCost,
-			})
+	chunks := c.planChunks(q)
+	if len(chunks) <= 1 {
+		return c.StreamAllocation(ctx, q, checked)
+	}
+	for _, ch := range chunks {
+		sub := q
+		sub.Window = ch.window()
+		if err := c.StreamAllocation(ctx, sub, checked); err != nil {
+			return fmt.Errorf("chunk %s: %w", sub.Window, err)
 		}
 	}
+	return nil
+}
 
-	return AllocationResponse{Items: items}
+// StreamAllocationPoints streams allocation points for q to emit as they are
+// decoded, fetching chunked windows sequentially like StreamAllocationEntries.
+func (c *Client) StreamAllocationPoints(
+	ctx context.Context,
+	q AllocationQuery,
+	emit func(AllocationPoint) error,
+) error {
+	return c.StreamAllocationEntries(ctx, q, func(_ int, _ string, entry AllocationEntry) error {
+		return emit(entry.ToPoint())
+	})
 }
 
-// EnhancedAllocation method that uses detailed allocation API to retrieve allocation data.
-func (c *Client) EnhancedAllocation(ctx context.Context, q AllocationQuery) (AllocationResponse, error) {
-	detailed, err := c.GetDetailedAllocation(ctx, q)
+// allocationPoints streams an allocation query straight into allocation points,
+// never materializing the detailed response. ctx carries EnhancedAllocation's
+// timeout.
+func (c *Client) allocationPoints(ctx context.Context, q AllocationQuery) ([]AllocationPoint, error) {
+	var items []AllocationPoint
+	_, err := c.streamAllocation(ctx, q, func(_ int, _ string, entry AllocationEntry) error {
+		items = append(items, entry.ToPoint())
+		return nil
+	})
 	if err != nil {
-		return AllocationResponse{}, err
+		return nil, err
 	}
-
-	return ConvertToSimpleResponse(detailed), nil
+	return items, nil
 }
 
 // FormatTimeWindow formats time window for Kubecost API.
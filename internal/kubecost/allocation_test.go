@@ -2,8 +2,13 @@ package kubecost //nolint:testpackage // Package name intentionally matches impl
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -121,6 +126,19 @@ func TestBuildAllocationURL(t *testing.T) {
 	}
 }
 
+func TestBuildAllocationURLNamespaces(t *testing.T) {
+	client := &Client{cfg: Config{BaseURL: "http://localhost:9090"}}
+
+	raw, err := client.BuildAllocationURL(AllocationQuery{Window: "30d", Namespaces: []string{"default", "prod"}})
+	if err != nil {
+		t.Fatalf("BuildAllocationURL failed: %v", err)
+	}
+	u, _ := url.Parse(raw)
+	if got := u.Query().Get("filter"); got != `namespace:"default","prod"` {
+		t.Errorf("Expected a filter matching either namespace, got %s", got)
+	}
+}
+
 func TestBuildAllocationURL_InvalidBaseURL(t *testing.T) {
 	client := &Client{
 		cfg: Config{
@@ -292,6 +310,34 @@ func TestConvertToSimpleResponse(t *testing.T) {
 	}
 }
 
+func TestToPointPVs(t *testing.T) {
+	var entry AllocationEntry
+	err := json.Unmarshal([]byte(`{
+		"pvCost": 1.5,
+		"pvs": {
+			"cluster=b:name=pvc-2": {"byteHours": 2147483648, "cost": 1, "adjustment": -0.25},
+			"cluster=a:name=pvc-1": {"byteHours": 1073741824, "cost": 0.75},
+			"local-pv": {"byteHours": 0, "cost": 0}
+		}
+	}`), &entry)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := []PVCost{
+		{Name: "local-pv"},
+		{Cluster: "a", Name: "pvc-1", GiBHours: 1, Cost: 0.75},
+		{Cluster: "b", Name: "pvc-2", GiBHours: 2, Cost: 0.75},
+	}
+	point := entry.ToPoint()
+	if !reflect.DeepEqual(point.PVs, want) {
+		t.Errorf("Expected PVs %+v, got %+v", want, point.PVs)
+	}
+	if point.PVCCost != 1.5 {
+		t.Errorf("Expected PVC cost 1.5, got %v", point.PVCCost)
+	}
+}
+
 func TestFormatTimeWindow(t *testing.T) {
 	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
@@ -421,3 +467,26 @@ func contains(s, substr string) bool {
 				return false
 			}())))
 }
+
+func TestGetDetailedAllocation_ErrorRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"error": "rejected %s", "access_token": "abc123"}`, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL, APIToken: "s3cr3t-token-value"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.GetDetailedAllocation(context.Background(), AllocationQuery{Window: "1d"})
+	if err == nil {
+		t.Fatal("Expected error for 401 status code")
+	}
+	for _, secret := range []string{"s3cr3t-token-value", "abc123"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("Expected %q to be redacted from %v", secret, err)
+		}
+	}
+}
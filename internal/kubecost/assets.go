@@ -0,0 +1,312 @@
+package kubecost
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Asset types reported in Asset.Type.
+const (
+	AssetNode              = "Node"
+	AssetDisk              = "Disk"
+	AssetLoadBalancer      = "LoadBalancer"
+	AssetNetwork           = "Network"
+	AssetClusterManagement = "ClusterManagement"
+)
+
+const (
+	minutesPerHour = 60
+	bytesPerGiB    = 1 << 30
+	// hoursPerMonth is the month length cloud providers bill storage by.
+	hoursPerMonth = 730
+)
+
+// AssetsQuery selects assets from the Kubecost assets API.
+type AssetsQuery struct {
+	Window      string            // same formats as AllocationQuery.Window
+	Types       []string          // asset types to return, e.g. AssetNode; all when empty
+	Filter      map[string]string // name, cluster, providerID, category, label[app], etc.
+	AggregateBy []string          // e.g. ["type", "cluster"]; individual assets when empty
+	Accumulate  bool              // one set for the whole window instead of one per day
+}
+
+// AssetsResponse is the assets API response. Data holds one asset set per
+// window step, keyed by asset identifier.
+type AssetsResponse struct {
+	Code    int                `json:"code"`
+	Status  string             `json:"status"`
+	Message string             `json:"message,omitempty"`
+	Data    []map[string]Asset `json:"data"`
+}
+
+// Asset is one Kubecost asset. Fields beyond the common ones are set only for
+// the asset types noted.
+type Asset struct {
+	Type       string            `json:"type"`
+	Properties AssetProperties   `json:"properties"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Window     AllocationWindow  `json:"window"`
+	Start      string            `json:"start"`
+	End        string            `json:"end"`
+	Minutes    float64           `json:"minutes"`
+	Adjustment float64           `json:"adjustment"`
+	TotalCost  float64           `json:"totalCost"`
+
+	// Node
+	NodeType     string  `json:"nodeType,omitempty"`
+	Preemptible  float64 `json:"preemptible,omitempty"` // fraction of the window the node was preemptible
+	CPUCores     float64 `json:"cpuCores,omitempty"`
+	RAMBytes     float64 `json:"ramBytes,omitempty"`
+	CPUCoreHours float64 `json:"cpuCoreHours,omitempty"`
+	RAMByteHours float64 `json:"ramByteHours,omitempty"`
+	GPUHours     float64 `json:"GPUHours,omitempty"`
+	GPUCount     float64 `json:"gpuCount,omitempty"`
+	CPUCost      float64 `json:"cpuCost,omitempty"`
+	RAMCost      float64 `json:"ramCost,omitempty"`
+	GPUCost      float64 `json:"gpuCost,omitempty"`
+	Discount     float64 `json:"discount,omitempty"`
+
+	// Disk
+	Bytes        float64 `json:"bytes,omitempty"`
+	ByteHours    float64 `json:"byteHours,omitempty"`
+	StorageClass string  `json:"storageClass,omitempty"`
+	VolumeName   string  `json:"volumeName,omitempty"`
+	ClaimName    string  `json:"claimName,omitempty"`
+	ClaimNS      string  `json:"claimNamespace,omitempty"`
+	Local        float64 `json:"local,omitempty"` // 1 for node-local disks
+
+	// LoadBalancer
+	Private bool   `json:"private,omitempty"`
+	IP      string `json:"ip,omitempty"`
+}
+
+// AssetProperties identifies an asset.
+type AssetProperties struct {
+	Category   string `json:"category,omitempty"` // Compute, Storage, Network, Management
+	Provider   string `json:"provider,omitempty"`
+	Account    string `json:"account,omitempty"`
+	Project    string `json:"project,omitempty"`
+	Service    string `json:"service,omitempty"`
+	Cluster    string `json:"cluster,omitempty"`
+	Name       string `json:"name,omitempty"`
+	ProviderID string `json:"providerID,omitempty"`
+}
+
+// Hours returns how long the asset ran within its window.
+func (a Asset) Hours() float64 {
+	return a.Minutes / minutesPerHour
+}
+
+// HourlyCost returns the asset's average cost per hour over its window.
+func (a Asset) HourlyCost() float64 {
+	return perUnit(a.TotalCost, a.Hours())
+}
+
+// CPUCoreHourlyRate returns a node's cost per CPU core-hour.
+func (a Asset) CPUCoreHourlyRate() float64 {
+	return perUnit(a.CPUCost, a.CPUCoreHours)
+}
+
+// RAMGiBHourlyRate returns a node's cost per GiB-hour of RAM.
+func (a Asset) RAMGiBHourlyRate() float64 {
+	return perUnit(a.RAMCost, a.RAMByteHours/bytesPerGiB)
+}
+
+// GPUHourlyRate returns a node's cost per GPU-hour.
+func (a Asset) GPUHourlyRate() float64 {
+	return perUnit(a.GPUCost, a.GPUHours)
+}
+
+// GiB returns a disk's provisioned size in GiB.
+func (a Asset) GiB() float64 {
+	return a.Bytes / bytesPerGiB
+}
+
+// GiBHours returns a disk's provisioned GiB-hours over its window.
+func (a Asset) GiBHours() float64 {
+	if a.ByteHours > 0 {
+		return a.ByteHours / bytesPerGiB
+	}
+	return a.GiB() * a.Hours()
+}
+
+// GiBMonthlyRate returns a disk's cost per GiB-month.
+func (a Asset) GiBMonthlyRate() float64 {
+	return perUnit(a.TotalCost, a.GiBHours()) * hoursPerMonth
+}
+
+func perUnit(cost, units float64) float64 {
+	if units <= 0 {
+		return 0
+	}
+	return cost / units
+}
+
+// Capacity types reported by Asset.CapacityType.
+const (
+	CapacityOnDemand = "on-demand"
+	CapacitySpot     = "spot"
+	CapacityReserved = "reserved"
+)
+
+// label returns the first of the named labels set on the asset. Kubecost
+// reports labels with Prometheus-safe names, sometimes prefixed with "label_".
+func (a Asset) label(keys ...string) string {
+	for _, k := range keys {
+		if v := a.Labels[k]; v != "" {
+			return v
+		}
+		if v := a.Labels["label_"+k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// InstanceType returns a node's cloud instance type, e.g. "m5.large".
+func (a Asset) InstanceType() string {
+	if a.NodeType != "" {
+		return a.NodeType
+	}
+	return a.label("node_kubernetes_io_instance_type", "beta_kubernetes_io_instance_type")
+}
+
+// CloudProvider returns "aws", "gcp" or "azure" from a node's provider ID,
+// falling back to the asset's provider property, or "" when it is neither.
+func (a Asset) CloudProvider() string {
+	scheme, _, _ := strings.Cut(a.Properties.ProviderID, "://")
+	switch strings.ToLower(scheme) {
+	case "aws":
+		return "aws"
+	case "gce":
+		return "gcp"
+	case "azure":
+		return "azure"
+	}
+	switch strings.ToLower(a.Properties.Provider) {
+	case "aws":
+		return "aws"
+	case "gcp", "gce", "google":
+		return "gcp"
+	case "azure":
+		return "azure"
+	}
+	return ""
+}
+
+// Zone returns a node's availability zone from its topology labels or, for
+// AWS ("aws:///us-east-1a/i-0abc") and GCP ("gce://project/us-central1-a/name"),
+// its provider ID.
+func (a Asset) Zone() string {
+	if z := a.label("topology_kubernetes_io_zone", "failure_domain_beta_kubernetes_io_zone"); z != "" {
+		return z
+	}
+	_, rest, ok := strings.Cut(a.Properties.ProviderID, "://")
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(strings.TrimPrefix(rest, "/"), "/")
+	switch {
+	case a.CloudProvider() == "aws" && len(parts) == 2:
+		return parts[0]
+	case a.CloudProvider() == "gcp" && len(parts) == 3:
+		return parts[1]
+	}
+	return ""
+}
+
+// Region returns a node's cloud region from its topology labels, or derives it
+// from the zone: "us-east-1a" on AWS, "us-central1-a" on GCP and "eastus-1" on
+// Azure.
+func (a Asset) Region() string {
+	if r := a.label("topology_kubernetes_io_region", "failure_domain_beta_kubernetes_io_region"); r != "" {
+		return r
+	}
+	zone := a.Zone()
+	if zone == "" {
+		return ""
+	}
+	if a.CloudProvider() == "aws" {
+		return strings.TrimRight(zone, "abcdefghijklmnopqrstuvwxyz")
+	}
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return ""
+}
+
+// CapacityType returns whether a node is CapacitySpot, CapacityReserved or
+// CapacityOnDemand, from the capacity labels set by Karpenter, EKS, GKE and AKS,
+// or from Kubecost's preemptible flag.
+func (a Asset) CapacityType() string {
+	capacity := a.label("karpenter_sh_capacity_type", "eks_amazonaws_com_capacityType",
+		"kubernetes_azure_com_scalesetpriority")
+	switch strings.ToLower(capacity) {
+	case "spot":
+		return CapacitySpot
+	case "reserved":
+		return CapacityReserved
+	case "on-demand", "on_demand", "regular":
+		return CapacityOnDemand
+	}
+	if a.label("cloud_google_com_gke_spot", "cloud_google_com_gke_preemptible") == "true" || a.Preemptible > 0 {
+		return CapacitySpot
+	}
+	return CapacityOnDemand
+}
+
+// BuildAssetsURL constructs the URL for the Kubecost assets API.
+func (c *Client) BuildAssetsURL(q AssetsQuery) (string, error) {
+	u, err := url.Parse(c.cfg.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	u.Path = assetsPath
+
+	params := url.Values{}
+	params.Set("window", q.Window)
+
+	var filters []string
+	if len(q.Types) > 0 {
+		types := make([]string, len(q.Types))
+		for i, t := range q.Types {
+			types[i] = strings.ToLower(t)
+		}
+		filters = append(filters, filterClause("assetType", types...))
+	}
+	filters = append(filters, filterClauses(q.Filter)...)
+	if len(filters) > 0 {
+		params.Set("filter", strings.Join(filters, "+"))
+	}
+
+	if len(q.AggregateBy) > 0 {
+		params.Set("aggregate", strings.Join(q.AggregateBy, ","))
+	}
+	params.Set("accumulate", fmt.Sprint(q.Accumulate))
+
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// Assets queries the Kubecost assets API for nodes, disks, load balancers,
+// network and cluster management costs.
+func (c *Client) Assets(ctx context.Context, q AssetsQuery) (*AssetsResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	url, err := c.BuildAssetsURL(q)
+	if err != nil {
+		return nil, err
+	}
+	var out AssetsResponse
+	if err = c.getJSON(ctx, assetsPath, url, &out); err != nil {
+		return nil, err
+	}
+	out.Message = c.redactor.String(out.Message)
+	if out.Code != httpSuccessStatus {
+		return nil, payloadError(assetsPath, out.Code, out.Message)
+	}
+	return &out, nil
+}
@@ -0,0 +1,196 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const assetsFixture = `{
+	"code": 200,
+	"status": "success",
+	"data": [
+		{
+			"cluster-one/ip-10-0-0-1": {
+				"type": "Node",
+				"properties": {"category": "Compute", "provider": "AWS", "cluster": "cluster-one",
+					"name": "ip-10-0-0-1", "providerID": "aws:///us-east-1a/i-0abc"},
+				"labels": {"node_kubernetes_io_instance_type": "m5.large"},
+				"window": {"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z"},
+				"start": "2024-01-01T00:00:00Z",
+				"end": "2024-01-02T00:00:00Z",
+				"minutes": 1440,
+				"nodeType": "m5.large",
+				"preemptible": 0,
+				"cpuCores": 2,
+				"ramBytes": 8589934592,
+				"cpuCoreHours": 48,
+				"ramByteHours": 206158430208,
+				"GPUHours": 0,
+				"cpuCost": 1.536,
+				"ramCost": 0.768,
+				"gpuCost": 0,
+				"totalCost": 2.304
+			},
+			"cluster-one/pvc-1": {
+				"type": "Disk",
+				"properties": {"category": "Storage", "cluster": "cluster-one", "name": "pvc-1"},
+				"minutes": 1440,
+				"bytes": 107374182400,
+				"storageClass": "gp3",
+				"claimName": "data",
+				"claimNamespace": "db",
+				"totalCost": 0.26
+			}
+		}
+	]
+}`
+
+func TestBuildAssetsURL(t *testing.T) {
+	client := &Client{cfg: Config{BaseURL: "http://kubecost:9090"}}
+	raw, err := client.BuildAssetsURL(AssetsQuery{
+		Window: "7d",
+		Types:  []string{AssetNode, AssetDisk},
+		Filter: map[string]string{"name": "ip-10-0-0-1", "cluster": "cluster-one"},
+	})
+	if err != nil {
+		t.Fatalf("BuildAssetsURL failed: %v", err)
+	}
+	u, _ := url.Parse(raw)
+	if u.Path != assetsPath {
+		t.Errorf("Expected path %s, got %s", assetsPath, u.Path)
+	}
+	q := u.Query()
+	if got := q.Get("filter"); got != `assetType:"node","disk"+cluster:"cluster-one"+name:"ip-10-0-0-1"` {
+		t.Errorf("Unexpected filter %s", got)
+	}
+	if q.Get("window") != "7d" || q.Get("accumulate") != "false" || q.Has("aggregate") {
+		t.Errorf("Unexpected query %s", u.RawQuery)
+	}
+}
+
+func TestAssets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != assetsPath {
+			t.Errorf("Expected path %s, got %s", assetsPath, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(assetsFixture))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	resp, err := client.Assets(context.Background(), AssetsQuery{Window: "1d"})
+	if err != nil {
+		t.Fatalf("Assets failed: %v", err)
+	}
+	if len(resp.Data) != 1 || len(resp.Data[0]) != 2 {
+		t.Fatalf("Expected one set of two assets, got %+v", resp.Data)
+	}
+
+	node := resp.Data[0]["cluster-one/ip-10-0-0-1"]
+	if node.Type != AssetNode || node.NodeType != "m5.large" || node.Properties.ProviderID != "aws:///us-east-1a/i-0abc" {
+		t.Errorf("Unexpected node %+v", node)
+	}
+	checks := map[string][2]float64{
+		"hours":          {node.Hours(), 24},
+		"hourly cost":    {node.HourlyCost(), 0.096},
+		"cpu core-hour":  {node.CPUCoreHourlyRate(), 0.032},
+		"ram GiB-hour":   {node.RAMGiBHourlyRate(), 0.004},
+		"gpu hour (n/a)": {node.GPUHourlyRate(), 0},
+	}
+	for name, c := range checks {
+		if math.Abs(c[0]-c[1]) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", name, c[1], c[0])
+		}
+	}
+
+	disk := resp.Data[0]["cluster-one/pvc-1"]
+	if disk.Type != AssetDisk || disk.StorageClass != "gp3" || disk.ClaimNS != "db" {
+		t.Errorf("Unexpected disk %+v", disk)
+	}
+	if disk.GiB() != 100 || disk.GiBHours() != 2400 {
+		t.Errorf("Expected 100 GiB over 2400 GiB-hours, got %v and %v", disk.GiB(), disk.GiBHours())
+	}
+	if got := disk.GiBMonthlyRate(); math.Abs(got-0.26/2400*730) > 1e-9 {
+		t.Errorf("Unexpected GiB-month rate %v", got)
+	}
+}
+
+func TestAssetsPayloadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"code": 400, "message": "invalid window"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	_, err = client.Assets(context.Background(), AssetsQuery{Window: "bogus"})
+	if !errors.Is(err, ErrBadQuery) {
+		t.Errorf("Expected a bad query error, got %v", err)
+	}
+}
+
+func TestAssetPlacement(t *testing.T) {
+	tests := []struct {
+		name                                       string
+		asset                                      Asset
+		provider, instance, region, zone, capacity string
+	}{
+		{
+			name: "aws provider ID",
+			asset: Asset{NodeType: "m5.large",
+				Properties: AssetProperties{ProviderID: "aws:///us-east-1a/i-0abc"}},
+			provider: "aws", instance: "m5.large", region: "us-east-1", zone: "us-east-1a", capacity: CapacityOnDemand,
+		},
+		{
+			name: "gcp spot labels",
+			asset: Asset{
+				Properties: AssetProperties{ProviderID: "gce://proj/us-central1-a/gke-node-1"},
+				Labels: map[string]string{"label_node_kubernetes_io_instance_type": "e2-standard-4",
+					"label_cloud_google_com_gke_spot": "true"},
+			},
+			provider: "gcp", instance: "e2-standard-4", region: "us-central1", zone: "us-central1-a", capacity: CapacitySpot,
+		},
+		{
+			name: "azure topology labels",
+			asset: Asset{
+				Properties: AssetProperties{ProviderID: "azure:///subscriptions/s/resourceGroups/rg/vm/0"},
+				Labels: map[string]string{"topology_kubernetes_io_zone": "eastus-1",
+					"kubernetes_azure_com_scalesetpriority": "spot"},
+			},
+			provider: "azure", region: "eastus", zone: "eastus-1", capacity: CapacitySpot,
+		},
+		{
+			name: "karpenter reserved",
+			asset: Asset{Properties: AssetProperties{Provider: "AWS"},
+				Labels: map[string]string{"karpenter_sh_capacity_type": "reserved",
+					"topology_kubernetes_io_region": "eu-west-1"}},
+			provider: "aws", region: "eu-west-1", capacity: CapacityReserved,
+		},
+		{
+			name:     "preemptible flag",
+			asset:    Asset{Preemptible: 1},
+			provider: "", capacity: CapacitySpot,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.asset
+			got := [5]string{a.CloudProvider(), a.InstanceType(), a.Region(), a.Zone(), a.CapacityType()}
+			want := [5]string{tt.provider, tt.instance, tt.region, tt.zone, tt.capacity}
+			if got != want {
+				t.Errorf("Expected provider, instance, region, zone, capacity %q, got %q", want, got)
+			}
+		})
+	}
+}
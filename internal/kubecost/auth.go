@@ -0,0 +1,283 @@
+package kubecost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/filereload"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/metrics"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/tracing"
+)
+
+// Authentication methods selectable with Config.AuthType.
+const (
+	AuthNone      = "none"
+	AuthBearer    = "bearer"
+	AuthTokenFile = "tokenFile"
+	AuthBasic     = "basic"
+	AuthOAuth2    = "oauth2"
+)
+
+// oauth2ExpirySkew refreshes OAuth2 tokens slightly before they expire.
+const oauth2ExpirySkew = 30 * time.Second
+
+// Authenticator adds credentials to an outgoing Kubecost request.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// invalidator is implemented by authenticators that cache credentials which
+// should be dropped after Kubecost answers 401.
+type invalidator interface {
+	Invalidate()
+}
+
+// newAuthenticator builds the authenticator selected by cfg. Static headers are
+// applied in addition to the selected credential method.
+func newAuthenticator(cfg Config, httpClient *http.Client) (Authenticator, error) {
+	var chain authChain
+	if len(cfg.Headers) > 0 {
+		chain = append(chain, staticHeaders(cfg.Headers))
+	}
+
+	method := cfg.AuthType
+	if method == "" {
+		method = inferAuthType(cfg)
+	}
+	switch method {
+	case AuthNone:
+	case AuthBearer:
+		if cfg.APIToken == "" {
+			return nil, errors.New("authType bearer requires apiToken")
+		}
+		chain = append(chain, bearerToken(cfg.APIToken))
+	case AuthTokenFile:
+		if cfg.APITokenFile == "" {
+			return nil, errors.New("authType tokenFile requires apiTokenFile")
+		}
+		tf, err := newTokenFile(cfg.APITokenFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, tf)
+	case AuthBasic:
+		if cfg.BasicAuthUsername == "" {
+			return nil, errors.New("authType basic requires basicAuthUsername")
+		}
+		chain = append(chain, basicAuth{username: cfg.BasicAuthUsername, password: cfg.BasicAuthPassword})
+	case AuthOAuth2:
+		if cfg.OAuth2TokenURL == "" || cfg.OAuth2ClientID == "" {
+			return nil, errors.New("authType oauth2 requires oauth2TokenUrl and oauth2ClientId")
+		}
+		chain = append(chain, &clientCredentials{
+			tokenURL:     cfg.OAuth2TokenURL,
+			clientID:     cfg.OAuth2ClientID,
+			clientSecret: cfg.OAuth2ClientSecret,
+			scopes:       cfg.OAuth2Scopes,
+			http:         httpClient,
+		})
+	default:
+		return nil, fmt.Errorf("unknown authType %q", method)
+	}
+
+	return chain, nil
+}
+
+// inferAuthType picks an authentication method from the fields that are set.
+func inferAuthType(cfg Config) string {
+	switch {
+	case cfg.OAuth2TokenURL != "":
+		return AuthOAuth2
+	case cfg.BasicAuthUsername != "":
+		return AuthBasic
+	case cfg.APITokenFile != "":
+		return AuthTokenFile
+	case cfg.APIToken != "":
+		return AuthBearer
+	}
+	return AuthNone
+}
+
+// authChain applies several authenticators in order.
+type authChain []Authenticator
+
+func (a authChain) Authenticate(req *http.Request) error {
+	for _, auth := range a {
+		if err := auth.Authenticate(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a authChain) Invalidate() {
+	for _, auth := range a {
+		if inv, ok := auth.(invalidator); ok {
+			inv.Invalidate()
+		}
+	}
+}
+
+// staticHeaders sets fixed headers such as X-Scope-OrgID.
+type staticHeaders map[string]string
+
+func (h staticHeaders) Authenticate(req *http.Request) error {
+	for k, v := range h {
+		req.Header.Set(k, v)
+	}
+	return nil
+}
+
+// bearerToken sends a static API token.
+type bearerToken string
+
+func (t bearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// basicAuth sends HTTP basic credentials.
+type basicAuth struct {
+	username, password string
+}
+
+func (b basicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(b.username, b.password)
+	return nil
+}
+
+// tokenFile sends a bearer token read from a file, re-reading it when the file
+// changes so projected service account tokens and rotated secrets keep working.
+// An unreadable or empty rewrite keeps the previous token.
+type tokenFile struct {
+	token *filereload.Value[string]
+}
+
+func newTokenFile(path string) (*tokenFile, error) {
+	token, err := filereload.New([]string{path}, func() (string, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		token := strings.TrimSpace(string(b))
+		if token == "" {
+			return "", fmt.Errorf("%s is empty", path)
+		}
+		return token, nil
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading apiTokenFile: %w", err)
+	}
+	return &tokenFile{token: token}, nil
+}
+
+func (f *tokenFile) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+f.token.Get())
+	return nil
+}
+
+func (f *tokenFile) Invalidate() {
+	f.token.Invalidate()
+}
+
+// clientCredentials fetches and caches OAuth2 access tokens using the client
+// credentials grant.
+type clientCredentials struct {
+	tokenURL, clientID, clientSecret string
+	scopes                           []string
+	http                             *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (c *clientCredentials) Authenticate(req *http.Request) error {
+	token, err := c.accessToken(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (c *clientCredentials) Invalidate() {
+	c.mu.Lock()
+	c.token = ""
+	c.mu.Unlock()
+}
+
+// accessToken returns the cached token or fetches a new one. Either way it is
+// traced, so slow token endpoints show up next to the Kubecost call they delay.
+func (c *clientCredentials) accessToken(ctx context.Context) (_ string, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "kubecost oauth2 token")
+	defer func() { tracing.End(span, err) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && (c.expiry.IsZero() || time.Now().Before(c.expiry)) {
+		span.SetAttributes(attribute.Bool(cacheHitAttr, true))
+		metrics.CacheLookup(oauth2TokenCache, true)
+		return c.token, nil
+	}
+	span.SetAttributes(attribute.Bool(cacheHitAttr, false))
+	metrics.CacheLookup(oauth2TokenCache, false)
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", &APIError{Kind: KindUnavailable, Endpoint: "oauth2 token", Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= httpRedirectStatus {
+		apiErr := statusError("oauth2 token", resp, readErrorBody(resp.Body))
+		if apiErr.Kind == KindBadQuery {
+			// RFC 6749 reports bad client credentials as 400 invalid_client
+			apiErr.Kind = KindUnauthorized
+		}
+		return "", apiErr
+	}
+
+	var tok oauth2TokenResponse
+	if err = json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", decodeError("oauth2 token", err)
+	}
+	if tok.AccessToken == "" {
+		return "", decodeError("oauth2 token", errors.New("response has no access_token"))
+	}
+
+	c.token = tok.AccessToken
+	c.expiry = time.Time{}
+	if tok.ExpiresIn > 0 {
+		c.expiry = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - oauth2ExpirySkew)
+	}
+	return c.token, nil
+}
@@ -0,0 +1,223 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newAuthTestServer records the headers of the last allocation request.
+func newAuthTestServer(t *testing.T) (*httptest.Server, func() http.Header) {
+	t.Helper()
+	var mu sync.Mutex
+	var last http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		last = r.Header.Clone()
+		mu.Unlock()
+		w.Write([]byte(`{"items": []}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+func TestBasicAuthAndHeaders(t *testing.T) {
+	server, last := newAuthTestServer(t)
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:           server.URL,
+		BasicAuthUsername: "kubecost",
+		BasicAuthPassword: "secret",
+		Headers:           map[string]string{"X-Scope-OrgID": "tenant-a"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("Allocation failed: %v", err)
+	}
+
+	req := &http.Request{Header: last()}
+	user, pass, ok := req.BasicAuth()
+	if !ok || user != "kubecost" || pass != "secret" {
+		t.Errorf("Expected basic auth kubecost/secret, got %q/%q (%v)", user, pass, ok)
+	}
+	if got := last().Get("X-Scope-OrgID"); got != "tenant-a" {
+		t.Errorf("Expected X-Scope-OrgID tenant-a, got %q", got)
+	}
+}
+
+func TestTokenFileRotation(t *testing.T) {
+	server, last := newAuthTestServer(t)
+	path := filepath.Join(t.TempDir(), "token")
+	writeFile(t, path, []byte("first\n"))
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL, APITokenFile: path})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("Allocation failed: %v", err)
+	}
+	if got := last().Get("Authorization"); got != "Bearer first" {
+		t.Errorf("Expected 'Bearer first', got %q", got)
+	}
+
+	writeFile(t, path, []byte("second"))
+	future := time.Now().Add(time.Minute)
+	if err = os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	client.auth.(authChain).Invalidate()
+
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("Allocation failed: %v", err)
+	}
+	if got := last().Get("Authorization"); got != "Bearer second" {
+		t.Errorf("Expected rotated 'Bearer second', got %q", got)
+	}
+
+	// An emptied file keeps the previous token
+	writeFile(t, path, []byte("\n"))
+	later := future.Add(time.Minute)
+	if err = os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	client.auth.(authChain).Invalidate()
+
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("Allocation failed: %v", err)
+	}
+	if got := last().Get("Authorization"); got != "Bearer second" {
+		t.Errorf("Expected an empty token file to keep 'Bearer second', got %q", got)
+	}
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	var mu sync.Mutex
+	issued := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || id != "plugin" || secret != "s3cret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		if r.FormValue("scope") != "read cost" {
+			t.Errorf("Expected scope 'read cost', got %q", r.FormValue("scope"))
+		}
+		mu.Lock()
+		issued++
+		n := issued
+		mu.Unlock()
+		w.Write([]byte(`{"access_token": "token-` + string(rune('0'+n)) + `", "expires_in": 3600}`))
+	}))
+	defer tokenServer.Close()
+
+	var seen []string
+	kubecost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("Authorization"))
+		first := len(seen) == 2
+		mu.Unlock()
+		if first {
+			// Reject the second call so the cached token is dropped
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer kubecost.Close()
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:            kubecost.URL,
+		OAuth2TokenURL:     tokenServer.URL,
+		OAuth2ClientID:     "plugin",
+		OAuth2ClientSecret: "s3cret",
+		OAuth2Scopes:       []string{"read", "cost"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err = client.Allocation(ctx, AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("first Allocation failed: %v", err)
+	}
+	if _, err = client.Allocation(ctx, AllocationQuery{Window: "1d"}); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got %v", err)
+	}
+	if _, err = client.Allocation(ctx, AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("third Allocation failed: %v", err)
+	}
+
+	want := []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}
+	for i, w := range want {
+		if seen[i] != w {
+			t.Errorf("request %d: expected %q, got %q", i, w, seen[i])
+		}
+	}
+}
+
+func TestOAuth2InvalidClient(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "invalid_client"}`))
+	}))
+	defer tokenServer.Close()
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:        "http://127.0.0.1:1",
+		OAuth2TokenURL: tokenServer.URL,
+		OAuth2ClientID: "wrong",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized for rejected client credentials, got %v", err)
+	}
+}
+
+func TestNewAuthenticatorErrors(t *testing.T) {
+	testCases := map[string]Config{
+		"unknown type":         {AuthType: "kerberos"},
+		"bearer without token": {AuthType: AuthBearer},
+		"missing token file":   {APITokenFile: filepath.Join(t.TempDir(), "missing")},
+		"oauth2 without id":    {AuthType: AuthOAuth2, OAuth2TokenURL: "http://idp"},
+	}
+	for name, cfg := range testCases {
+		if _, err := NewClient(context.Background(), cfg); err == nil {
+			t.Errorf("%s: expected NewClient to fail", name)
+		}
+	}
+}
+
+func TestAuthConfigFromEnvironment(t *testing.T) {
+	t.Setenv("KUBECOST_OAUTH2_SCOPES", "read, cost")
+	t.Setenv("KUBECOST_HEADERS", "X-Scope-OrgID=tenant-a,X-Team=platform")
+	t.Setenv("KUBECOST_API_TOKEN_FILE", "/var/run/secrets/kubecost/token")
+
+	cfg, err := LoadConfigFromEnvOrFile("")
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnvOrFile failed: %v", err)
+	}
+	if len(cfg.OAuth2Scopes) != 2 || cfg.OAuth2Scopes[1] != "cost" {
+		t.Errorf("Expected scopes [read cost], got %v", cfg.OAuth2Scopes)
+	}
+	if cfg.Headers["X-Scope-OrgID"] != "tenant-a" || cfg.Headers["X-Team"] != "platform" {
+		t.Errorf("Unexpected headers %v", cfg.Headers)
+	}
+	if cfg.APITokenFile != "/var/run/secrets/kubecost/token" {
+		t.Errorf("Expected apiTokenFile from environment, got %q", cfg.APITokenFile)
+	}
+}
@@ -0,0 +1,197 @@
+package kubecost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Chunk failure policies control how EnhancedAllocation reacts when some chunks
+// of a split window fail.
+const (
+	// ChunkPolicyFail aborts the whole query on the first failed chunk.
+	ChunkPolicyFail = "fail"
+	// ChunkPolicyPartial returns the chunks that succeeded and records the failed
+	// windows in AllocationResponse.FailedWindows.
+	ChunkPolicyPartial = "partial"
+)
+
+const (
+	defaultChunkConcurrency = 4
+	hoursPerDay             = 24
+)
+
+// allocationChunk is one sub-window of a chunked allocation query.
+type allocationChunk struct {
+	start, end time.Time
+}
+
+func (ch allocationChunk) window() string {
+	return FormatTimeWindow(ch.start, ch.end)
+}
+
+// chunkResult holds the outcome of fetching a single chunk.
+type chunkResult struct {
+	items []AllocationPoint
+	err   error
+}
+
+// planChunks splits the window of q into chunks no longer than the configured
+// chunk window. It returns nil when chunking is disabled, the window cannot be
+// resolved to absolute times, or the window already fits in a single chunk.
+func (c *Client) planChunks(q AllocationQuery) []allocationChunk {
+	if c.cfg.ChunkWindow == "" {
+		return nil
+	}
+	size, err := parseWindowDuration(c.cfg.ChunkWindow)
+	if err != nil || size <= 0 {
+		return nil
+	}
+	start, end, err := resolveWindow(q.Window)
+	if err != nil || end.Sub(start) <= size {
+		return nil
+	}
+
+	var chunks []allocationChunk
+	for cs := start; cs.Before(end); cs = cs.Add(size) {
+		ce := cs.Add(size)
+		if ce.After(end) {
+			ce = end
+		}
+		chunks = append(chunks, allocationChunk{start: cs, end: ce})
+	}
+	return chunks
+}
+
+// chunkedAllocation fetches each chunk concurrently with a bounded worker pool
+// and stitches the results back together in chronological order.
+func (c *Client) chunkedAllocation(
+	ctx context.Context,
+	q AllocationQuery,
+	chunks []allocationChunk,
+) (AllocationResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := c.cfg.ChunkConcurrency
+	if workers <= 0 {
+		workers = defaultChunkConcurrency
+	}
+	workers = min(workers, len(chunks))
+	failFast := c.cfg.ChunkFailurePolicy != ChunkPolicyPartial
+
+	results := make([]chunkResult, len(chunks))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sub := q
+				sub.Window = chunks[i].window()
+				items, err := c.allocationPoints(ctx, sub)
+				if err != nil {
+					results[i].err = fmt.Errorf("chunk %s: %w", sub.Window, err)
+					if failFast {
+						cancel()
+					}
+					continue
+				}
+				results[i].items = items
+			}
+		}()
+	}
+
+	sent := 0
+dispatch:
+	for ; sent < len(chunks); sent++ {
+		select {
+		case indexes <- sent:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Chunks never handed to a worker failed too, so a deadline reached
+	// mid-query is not mistaken for a complete result
+	for i := sent; i < len(chunks); i++ {
+		results[i].err = fmt.Errorf("chunk %s: %w", chunks[i].window(), ctx.Err())
+	}
+
+	return stitchChunks(chunks, results, failFast)
+}
+
+// stitchChunks combines per-chunk results according to the failure policy.
+func stitchChunks(chunks []allocationChunk, results []chunkResult, failFast bool) (AllocationResponse, error) {
+	var out AllocationResponse
+	var errs []error
+	for i, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			out.FailedWindows = append(out.FailedWindows, chunks[i].window())
+			continue
+		}
+		out.Items = append(out.Items, r.items...)
+	}
+
+	if len(errs) == 0 {
+		return out, nil
+	}
+	if failFast {
+		return AllocationResponse{}, firstCauseError(errs)
+	}
+	if len(out.FailedWindows) == len(chunks) {
+		return AllocationResponse{}, fmt.Errorf("all %d chunks failed: %w", len(chunks), errors.Join(errs...))
+	}
+	return out, nil
+}
+
+// firstCauseError prefers an error that is not a cancellation triggered by a
+// sibling chunk failing, so the caller sees the root cause.
+func firstCauseError(errs []error) error {
+	for _, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	return errs[0]
+}
+
+// resolveWindow converts a Kubecost window ("30d", "24h" or "start,end" in
+// RFC3339) into absolute start and end times.
+func resolveWindow(window string) (time.Time, time.Time, error) {
+	if startStr, endStr, ok := strings.Cut(window, ","); ok {
+		start, err := time.Parse(time.RFC3339, strings.TrimSpace(startStr))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid window start: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, strings.TrimSpace(endStr))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid window end: %w", err)
+		}
+		return start.UTC(), end.UTC(), nil
+	}
+	return ParseDurationWindow(window)
+}
+
+// parseWindowDuration parses a window size such as "7d" or "12h".
+func parseWindowDuration(window string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		var d int
+		if _, err := fmt.Sscanf(days, "%d", &d); err != nil {
+			return 0, fmt.Errorf("invalid duration format: %s", window)
+		}
+		return time.Duration(d) * hoursPerDay * time.Hour, nil
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration format: %s", window)
+	}
+	return d, nil
+}
@@ -0,0 +1,288 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chunkServer responds to allocation queries with one entry whose start is the
+// start of the requested window, failing any window whose start is in failStarts.
+func chunkServer(t *testing.T, failStarts map[string]bool, calls *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		start, end, _ := strings.Cut(r.URL.Query().Get("window"), ",")
+		if failStarts[start] {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"code": 200, "data": [{"a": {"start": %q, "end": %q, "totalCost": 1}}]}`, start, end)
+	}))
+}
+
+func TestPlanChunks(t *testing.T) {
+	client := &Client{cfg: Config{ChunkWindow: "7d"}}
+
+	chunks := client.planChunks(AllocationQuery{Window: "2024-01-01T00:00:00Z,2024-01-20T00:00:00Z"})
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", len(chunks))
+	}
+	if got := chunks[2].window(); got != "2024-01-15T00:00:00Z,2024-01-20T00:00:00Z" {
+		t.Errorf("Expected last chunk to be truncated at window end, got %s", got)
+	}
+
+	// Windows that fit in one chunk are not split
+	if chunks = client.planChunks(AllocationQuery{Window: "3d"}); chunks != nil {
+		t.Errorf("Expected no chunks for short window, got %d", len(chunks))
+	}
+
+	// Named Kubecost windows cannot be resolved and pass through unchanged
+	if chunks = client.planChunks(AllocationQuery{Window: "lastweek"}); chunks != nil {
+		t.Errorf("Expected no chunks for named window, got %d", len(chunks))
+	}
+
+	// Chunking disabled
+	client.cfg.ChunkWindow = ""
+	if chunks = client.planChunks(AllocationQuery{Window: "365d"}); chunks != nil {
+		t.Errorf("Expected no chunks when chunking is disabled, got %d", len(chunks))
+	}
+}
+
+func TestEnhancedAllocationChunked(t *testing.T) {
+	var calls int32
+	server := chunkServer(t, nil, &calls)
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:          server.URL,
+		ChunkWindow:      "1d",
+		ChunkConcurrency: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	resp, err := client.EnhancedAllocation(context.Background(), AllocationQuery{
+		Window: "2024-01-01T00:00:00Z,2024-01-06T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("EnhancedAllocation failed: %v", err)
+	}
+
+	if calls != 5 {
+		t.Errorf("Expected 5 chunk requests, got %d", calls)
+	}
+	if len(resp.Items) != 5 {
+		t.Fatalf("Expected 5 items, got %d", len(resp.Items))
+	}
+
+	// Results are stitched back in chronological order
+	for i, item := range resp.Items {
+		want := time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		if item.Start != want {
+			t.Errorf("Item %d: expected start %s, got %s", i, want, item.Start)
+		}
+	}
+}
+
+func TestEnhancedAllocationChunkFailurePolicies(t *testing.T) {
+	var calls int32
+	server := chunkServer(t, map[string]bool{"2024-01-02T00:00:00Z": true}, &calls)
+	defer server.Close()
+
+	query := AllocationQuery{Window: "2024-01-01T00:00:00Z,2024-01-04T00:00:00Z"}
+
+	failClient, err := NewClient(context.Background(), Config{
+		BaseURL:     server.URL,
+		ChunkWindow: "1d",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = failClient.EnhancedAllocation(context.Background(), query); err == nil {
+		t.Error("Expected error with fail policy")
+	} else if !strings.Contains(err.Error(), "2024-01-02T00:00:00Z") {
+		t.Errorf("Expected error to name the failed chunk, got %v", err)
+	}
+
+	partialClient, err := NewClient(context.Background(), Config{
+		BaseURL:            server.URL,
+		ChunkWindow:        "1d",
+		ChunkFailurePolicy: ChunkPolicyPartial,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	resp, err := partialClient.EnhancedAllocation(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Expected partial success, got %v", err)
+	}
+	if len(resp.Items) != 2 {
+		t.Errorf("Expected 2 items from successful chunks, got %d", len(resp.Items))
+	}
+	if len(resp.FailedWindows) != 1 || resp.FailedWindows[0] != "2024-01-02T00:00:00Z,2024-01-03T00:00:00Z" {
+		t.Errorf("Expected failed window to be recorded, got %v", resp.FailedWindows)
+	}
+}
+
+func TestParseWindowDuration(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+	for in, want := range testCases {
+		got, err := parseWindowDuration(in)
+		if err != nil {
+			t.Errorf("parseWindowDuration(%s) failed: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseWindowDuration(%s): expected %v, got %v", in, want, got)
+		}
+	}
+
+	if _, err := parseWindowDuration("xd"); err == nil {
+		t.Error("Expected error for invalid day count")
+	}
+}
+
+func TestStreamAllocationPointsChunked(t *testing.T) {
+	var calls int32
+	server := chunkServer(t, nil, &calls)
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL, ChunkWindow: "1d"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	var starts []string
+	err = client.StreamAllocationPoints(context.Background(), AllocationQuery{
+		Window: "2024-01-01T00:00:00Z,2024-01-04T00:00:00Z",
+	}, func(p AllocationPoint) error {
+		starts = append(starts, p.Start)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAllocationPoints failed: %v", err)
+	}
+
+	want := "2024-01-01T00:00:00Z,2024-01-02T00:00:00Z,2024-01-03T00:00:00Z"
+	if strings.Join(starts, ",") != want {
+		t.Errorf("Expected chunks streamed in order %s, got %v", want, starts)
+	}
+}
+
+// newSlowChunkClient returns a client with a 150ms timeout splitting windows
+// into daily chunks served in 60ms each.
+func newSlowChunkClient(t *testing.T, concurrency int) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(60 * time.Millisecond)
+		start, end, _ := strings.Cut(r.URL.Query().Get("window"), ",")
+		fmt.Fprintf(w, `{"code": 200, "data": [{"a": {"start": %q, "end": %q, "totalCost": 1}}]}`, start, end)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:          server.URL,
+		ChunkWindow:      "1d",
+		ChunkConcurrency: concurrency,
+		Timeout:          150 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client
+}
+
+func TestChunksShareTimeout(t *testing.T) {
+	// Each chunk fits in the timeout, the three in a row do not
+	client := newSlowChunkClient(t, 1)
+	_, err := client.EnhancedAllocation(context.Background(), AllocationQuery{
+		Window: "2024-01-01T00:00:00Z,2024-01-04T00:00:00Z",
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the chunks to exhaust one shared timeout, got %v", err)
+	}
+}
+
+func TestPartialChunksCutShortByDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(60 * time.Millisecond)
+		start, end, _ := strings.Cut(r.URL.Query().Get("window"), ",")
+		fmt.Fprintf(w, `{"code": 200, "data": [{"a": {"start": %q, "end": %q, "totalCost": 1}}]}`, start, end)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:            server.URL,
+		ChunkWindow:        "1d",
+		ChunkConcurrency:   1,
+		ChunkFailurePolicy: ChunkPolicyPartial,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	// The deadline passes after a chunk or two, before the rest are dispatched
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	resp, err := client.EnhancedAllocation(ctx, AllocationQuery{
+		Window: "2024-01-01T00:00:00Z,2024-01-07T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("Expected partial success, got %v", err)
+	}
+
+	fetched := map[string]bool{}
+	for _, item := range resp.Items {
+		fetched[item.Start] = true
+	}
+	failed := map[string]bool{}
+	for _, w := range resp.FailedWindows {
+		failed[w] = true
+	}
+	for day := range 6 {
+		start := time.Date(2024, 1, 1+day, 0, 0, 0, 0, time.UTC)
+		window := FormatTimeWindow(start, start.AddDate(0, 0, 1))
+		if fetched[start.Format(time.RFC3339)] == failed[window] {
+			t.Errorf("Expected window %s to be either fetched or failed, got items %v and failed %v",
+				window, resp.Items, resp.FailedWindows)
+		}
+	}
+	if len(resp.FailedWindows) < 4 {
+		t.Errorf("Expected the undispatched chunks to be reported failed, got %v", resp.FailedWindows)
+	}
+}
+
+func TestStreamChunksTimedSeparately(t *testing.T) {
+	client := newSlowChunkClient(t, 1)
+	query := AllocationQuery{Window: "2024-01-01T00:00:00Z,2024-01-04T00:00:00Z"}
+
+	// Without a deadline, each chunk gets its own timeout
+	err := client.StreamAllocationPoints(context.Background(), query, func(AllocationPoint) error { return nil })
+	if err != nil {
+		t.Errorf("Expected each streamed chunk to get its own timeout, got %v", err)
+	}
+
+	// With one, a consumer slower than the timeout only has to beat the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = client.StreamAllocationPoints(ctx, query, func(AllocationPoint) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected the stream to be bounded by its deadline alone, got %v", err)
+	}
+}
@@ -4,9 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
 )
 
 const (
@@ -14,43 +21,173 @@ const (
 	httpClientError    = 400
 )
 
+// Kubecost API endpoints.
+const (
+	allocationPath = "/model/allocation"
+	predictionPath = "/model/prediction/speccost"
+	assetsPath     = "/model/assets"
+	cloudCostPath  = "/model/cloudCost"
+	sizingPath     = "/model/savings/requestSizingV2"
+)
+
 type Client struct {
-	cfg  Config
-	http *http.Client
+	cfg      Config
+	http     *http.Client
+	auth     Authenticator
+	limiter  *limiter
+	redactor *logging.Redactor
 }
 
 func NewClient(_ context.Context, cfg Config) (*Client, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("building http client: %w", err)
+	}
+	auth, err := newAuthenticator(cfg, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("configuring authentication: %w", err)
+	}
 	return &Client{
-		cfg:  cfg,
-		http: &http.Client{},
+		cfg:      cfg,
+		http:     httpClient,
+		auth:     auth,
+		limiter:  newLimiter(cfg),
+		redactor: logging.NewRedactor(cfg.Secrets()...),
 	}, nil
 }
 
+// Close releases the client's idle connections. Requests in flight are not
+// interrupted, so a client replaced by a config reload can be closed at once.
+func (c *Client) Close() {
+	if c.http != nil {
+		c.http.CloseIdleConnections()
+	}
+}
+
 // GetConfig returns the client configuration.
 func (c *Client) GetConfig() Config {
 	return c.cfg
 }
 
+// do authenticates req and sends it once the client's rate limiter and
+// concurrency cap allow it. The concurrency slot is held until the response
+// body is closed. Each call is logged at debug level with its latency, and
+// failures at warn level, through the request's logger, counted in the
+// request metrics, and traced with a client span that ends when the body is
+// closed.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx, span := startSpan(req)
+	req = req.WithContext(ctx)
+	start := time.Now()
+	resp, err := c.send(req)
+	latency := time.Since(start)
+	logging.ObserveUpstream(ctx, latency)
+
+	log := logging.FromContext(ctx)
+	attrs := []any{"method", req.Method, "endpoint", req.URL.Path, "kubecost_latency", latency}
+	switch {
+	case err != nil:
+		log.WarnContext(ctx, "kubecost request failed", append(attrs, "error", c.redactor.String(err.Error()))...)
+	case resp.StatusCode >= httpClientError:
+		log.WarnContext(ctx, "kubecost request failed", append(attrs, "status", resp.StatusCode)...)
+	default:
+		log.DebugContext(ctx, "kubecost request", append(attrs, "status", resp.StatusCode)...)
+	}
+	if err != nil {
+		observeFailure(span, req, latency, err)
+		return nil, err
+	}
+	return observeResponse(span, req, resp, latency), nil
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.http == nil {
+		return nil, errors.New("kubecost client not initialized, use NewClient")
+	}
+	auth := c.authenticator()
+	if err := auth.Authenticate(req); err != nil {
+		return nil, err
+	}
+	release, err := c.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		if inv, ok := auth.(invalidator); ok {
+			inv.Invalidate()
+		}
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// withTimeout bounds ctx by Config.Timeout for one client operation. The
+// earlier deadline wins, so an RPC deadline shorter than the timeout applies,
+// and requests made for the operation, such as the chunks of a chunked query,
+// share what is left of its budget rather than each getting a full timeout.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.cfg.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.cfg.Timeout)
+}
+
+// errorBody reads an error response body for an APIError message, masking
+// credentials Kubecost or a proxy may have echoed back.
+func (c *Client) errorBody(r io.Reader) string {
+	return c.redactor.String(readErrorBody(r))
+}
+
+// authenticator returns the configured authenticator, falling back to the
+// static API token for clients not built with NewClient.
+func (c *Client) authenticator() Authenticator {
+	if c.auth != nil {
+		return c.auth
+	}
+	if c.cfg.APIToken != "" {
+		return bearerToken(c.cfg.APIToken)
+	}
+	return authChain{}
+}
+
 type AllocationQuery struct {
 	Window      string            // "2025-07-01T00:00:00Z,2025-07-31T23:59:59Z" or "30d"
 	Filter      map[string]string // namespace, controller, pod, cluster, label:app, node, etc.
+	Namespaces  []string          // any of these namespaces, on top of Filter; all when empty
 	AggregateBy []string          // e.g., ["namespace", "controller"]
 }
 
 type AllocationPoint struct {
-	Start       string  `json:"start"`
-	End         string  `json:"end"`
-	Cost        float64 `json:"cost"`
-	CPUCost     float64 `json:"cpuCost"`
-	RAMCost     float64 `json:"ramCost"`
-	GPUCost     float64 `json:"gpuCost"`
-	PVCCost     float64 `json:"pvcCost"`
-	NetworkCost float64 `json:"networkCost"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Cost        float64  `json:"cost"`
+	CPUCost     float64  `json:"cpuCost"`
+	RAMCost     float64  `json:"ramCost"`
+	GPUCost     float64  `json:"gpuCost"`
+	PVCCost     float64  `json:"pvcCost"`
+	PVs         []PVCost `json:"pvs,omitempty"`
+	NetworkCost float64  `json:"networkCost"`
 	// ... add fields as needed
 }
 
+// PVCost is an allocation point's cost for one persistent volume.
+type PVCost struct {
+	Cluster  string  `json:"cluster,omitempty"`
+	Name     string  `json:"name"`
+	GiBHours float64 `json:"gibHours"`
+	Cost     float64 `json:"cost"`
+}
+
 type AllocationResponse struct {
 	Items []AllocationPoint `json:"items"`
+	// FailedWindows lists chunk windows that could not be fetched when the
+	// partial chunk failure policy is in effect.
+	FailedWindows []string `json:"failedWindows,omitempty"`
 }
 
 // PredictionRequest represents the request for cost prediction API.
@@ -70,25 +207,24 @@ type PredictionResponse struct {
 }
 
 func (c *Client) Allocation(ctx context.Context, q AllocationQuery) (AllocationResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 	url, err := c.BuildAllocationURL(q)
 	if err != nil {
 		return AllocationResponse{}, err
 	}
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if c.cfg.APIToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.cfg.APIToken)
-	}
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return AllocationResponse{}, err
+		return AllocationResponse{}, transportError(allocationPath, err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= httpRedirectStatus {
-		return AllocationResponse{}, fmt.Errorf("kubecost %d", resp.StatusCode)
+		return AllocationResponse{}, statusError(allocationPath, resp, c.errorBody(resp.Body))
 	}
 	var out AllocationResponse
 	if decodeErr := json.NewDecoder(resp.Body).Decode(&out); decodeErr != nil {
-		return out, decodeErr
+		return out, decodeError(allocationPath, decodeErr)
 	}
 	return out, nil
 }
@@ -96,12 +232,15 @@ func (c *Client) Allocation(ctx context.Context, q AllocationQuery) (AllocationR
 // PredictSpecCost sends a workload specification to the Kubecost prediction API
 // and returns the predicted cost impact.
 func (c *Client) PredictSpecCost(ctx context.Context, req PredictionRequest) (PredictionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	// Build the prediction API URL
 	u, err := url.Parse(c.cfg.BaseURL)
 	if err != nil {
 		return PredictionResponse{}, fmt.Errorf("invalid base URL: %w", err)
 	}
-	u.Path = "/model/prediction/speccost"
+	u.Path = predictionPath
 
 	// Build query parameters
 	params := url.Values{}
@@ -138,26 +277,23 @@ func (c *Client) PredictSpecCost(ctx context.Context, req PredictionRequest) (Pr
 	// Set headers
 	httpReq.Header.Set("Content-Type", contentType)
 	httpReq.Header.Set("Accept", "application/json")
-	if c.cfg.APIToken != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+c.cfg.APIToken)
-	}
 
 	// Execute the request
-	resp, err := c.http.Do(httpReq)
+	resp, err := c.do(httpReq)
 	if err != nil {
-		return PredictionResponse{}, fmt.Errorf("executing request: %w", err)
+		return PredictionResponse{}, transportError(predictionPath, err)
 	}
 	defer resp.Body.Close()
 
 	// Check for HTTP errors
 	if resp.StatusCode >= httpClientError {
-		return PredictionResponse{}, fmt.Errorf("kubecost prediction API error: status=%d", resp.StatusCode)
+		return PredictionResponse{}, statusError(predictionPath, resp, c.errorBody(resp.Body))
 	}
 
 	// Decode the response
 	var predResp PredictionResponse
 	if decodeErr := json.NewDecoder(resp.Body).Decode(&predResp); decodeErr != nil {
-		return PredictionResponse{}, fmt.Errorf("decoding response: %w", decodeErr)
+		return PredictionResponse{}, decodeError(predictionPath, decodeErr)
 	}
 
 	return predResp, nil
@@ -168,3 +304,64 @@ func isJSON(s string) bool {
 	var js json.RawMessage
 	return json.Unmarshal([]byte(s), &js) == nil
 }
+
+// filterClause returns a filter matching any of values for key, e.g.
+// `assetType:"node","disk"`.
+func filterClause(key string, values ...string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return key + ":" + strings.Join(quoted, ",")
+}
+
+// filterClauses returns one clause per filter entry, ordered by key.
+func filterClauses(filter map[string]string) []string {
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = filterClause(k, filter[k])
+	}
+	return out
+}
+
+// getJSON GETs url and decodes its JSON body into out, reporting failures
+// against endpoint.
+func (c *Client) getJSON(ctx context.Context, endpoint, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if !c.cfg.DisableCompression {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return transportError(endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := decodedBody(resp)
+	if err != nil {
+		return decodeError(endpoint, err)
+	}
+	defer body.Close()
+
+	if resp.StatusCode >= httpClientErrorStatus {
+		return statusError(endpoint, resp, c.errorBody(body))
+	}
+
+	if err = json.NewDecoder(body).Decode(out); err != nil {
+		if ctx.Err() != nil {
+			return transportError(endpoint, ctx.Err())
+		}
+		return decodeError(endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package kubecost
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CloudCostQuery selects line items from the Kubecost cloud cost API, which
+// reports the cloud bill Kubecost ingests, including resources outside the
+// cluster. Each list filter matches any of its values; filters are combined.
+type CloudCostQuery struct {
+	Window      string            // same formats as AllocationQuery.Window
+	Providers   []string          // e.g. "AWS", "GCP", "Azure"
+	Accounts    []string          // account, project or subscription IDs
+	Services    []string          // e.g. "AmazonRDS", "AmazonS3"
+	ProviderIDs []string          // cloud resource IDs such as ARNs
+	Labels      map[string]string // resource tags, matched exactly
+	AggregateBy []string          // e.g. ["providerID"]; every property when empty
+	Accumulate  bool              // one set for the whole window instead of one per day
+}
+
+// CloudCostResponse is the cloud cost API response.
+type CloudCostResponse struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message,omitempty"`
+	Data    CloudCostData `json:"data"`
+}
+
+// CloudCostData holds one cloud cost set per window step.
+type CloudCostData struct {
+	Sets   []CloudCostSet   `json:"sets"`
+	Window AllocationWindow `json:"window"`
+}
+
+// CloudCostSet holds the cloud costs of one window step, keyed by the
+// aggregated properties.
+type CloudCostSet struct {
+	CloudCosts map[string]CloudCost `json:"cloudCosts"`
+	Window     AllocationWindow     `json:"window"`
+}
+
+// CloudCost is the cost of one billing line item, or of several aggregated.
+// Its metrics price the same usage at list price, net of discounts, amortized
+// over commitments and as invoiced.
+type CloudCost struct {
+	Properties       CloudCostProperties `json:"properties"`
+	Window           AllocationWindow    `json:"window"`
+	ListCost         CostMetric          `json:"listCost"`
+	NetCost          CostMetric          `json:"netCost"`
+	AmortizedNetCost CostMetric          `json:"amortizedNetCost"`
+	InvoicedCost     CostMetric          `json:"invoicedCost"`
+	AmortizedCost    CostMetric          `json:"amortizedCost"`
+}
+
+// CloudCostProperties identifies a cloud cost.
+type CloudCostProperties struct {
+	Provider        string            `json:"provider,omitempty"`
+	ProviderID      string            `json:"providerID,omitempty"`
+	AccountID       string            `json:"accountID,omitempty"`
+	AccountName     string            `json:"accountName,omitempty"`
+	InvoiceEntityID string            `json:"invoiceEntityID,omitempty"`
+	Service         string            `json:"service,omitempty"`
+	Category        string            `json:"category,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+}
+
+// CostMetric is a cost and the share of it spent on Kubernetes.
+type CostMetric struct {
+	Cost              float64 `json:"cost"`
+	KubernetesPercent float64 `json:"kubernetesPercent"`
+}
+
+// BuildCloudCostURL constructs the URL for the Kubecost cloud cost API.
+func (c *Client) BuildCloudCostURL(q CloudCostQuery) (string, error) {
+	u, err := url.Parse(c.cfg.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	u.Path = cloudCostPath
+
+	params := url.Values{}
+	params.Set("window", q.Window)
+
+	var filters []string
+	for _, f := range []struct {
+		key    string
+		values []string
+	}{
+		{"provider", q.Providers},
+		{"accountID", q.Accounts},
+		{"service", q.Services},
+		{"providerID", q.ProviderIDs},
+	} {
+		if len(f.values) > 0 {
+			filters = append(filters, filterClause(f.key, f.values...))
+		}
+	}
+	labels := make(map[string]string, len(q.Labels))
+	for k, v := range q.Labels {
+		labels["label["+k+"]"] = v
+	}
+	filters = append(filters, filterClauses(labels)...)
+	if len(filters) > 0 {
+		params.Set("filter", strings.Join(filters, "+"))
+	}
+
+	if len(q.AggregateBy) > 0 {
+		params.Set("aggregate", strings.Join(q.AggregateBy, ","))
+	}
+	params.Set("accumulate", fmt.Sprint(q.Accumulate))
+
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// CloudCost queries the Kubecost cloud cost API for billed cloud resources,
+// such as databases, buckets and load balancers created outside the cluster.
+func (c *Client) CloudCost(ctx context.Context, q CloudCostQuery) (*CloudCostResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	url, err := c.BuildCloudCostURL(q)
+	if err != nil {
+		return nil, err
+	}
+	var out CloudCostResponse
+	if err = c.getJSON(ctx, cloudCostPath, url, &out); err != nil {
+		return nil, err
+	}
+	out.Message = c.redactor.String(out.Message)
+	if out.Code != httpSuccessStatus {
+		return nil, payloadError(cloudCostPath, out.Code, out.Message)
+	}
+	return &out, nil
+}
@@ -0,0 +1,106 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const cloudCostFixture = `{
+	"code": 200,
+	"data": {
+		"sets": [{
+			"cloudCosts": {
+				"arn:aws:rds:us-east-1:123:db:orders": {
+					"properties": {"provider": "AWS", "providerID": "arn:aws:rds:us-east-1:123:db:orders",
+						"accountID": "123", "service": "AmazonRDS", "category": "Storage",
+						"labels": {"stack": "shop"}},
+					"window": {"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z"},
+					"listCost": {"cost": 12, "kubernetesPercent": 0},
+					"netCost": {"cost": 10, "kubernetesPercent": 0},
+					"amortizedNetCost": {"cost": 9.5, "kubernetesPercent": 0},
+					"invoicedCost": {"cost": 10, "kubernetesPercent": 0},
+					"amortizedCost": {"cost": 11, "kubernetesPercent": 0}
+				}
+			},
+			"window": {"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z"}
+		}],
+		"window": {"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z"}
+	}
+}`
+
+func TestBuildCloudCostURL(t *testing.T) {
+	client := &Client{cfg: Config{BaseURL: "http://kubecost:9090"}}
+	raw, err := client.BuildCloudCostURL(CloudCostQuery{
+		Window:      "7d",
+		Providers:   []string{"AWS"},
+		Accounts:    []string{"123", "456"},
+		Services:    []string{"AmazonRDS"},
+		ProviderIDs: []string{"arn:aws:rds:us-east-1:123:db:orders"},
+		Labels:      map[string]string{"stack": "shop", "env": "prod"},
+		AggregateBy: []string{"providerID"},
+	})
+	if err != nil {
+		t.Fatalf("BuildCloudCostURL failed: %v", err)
+	}
+	u, _ := url.Parse(raw)
+	if u.Path != cloudCostPath {
+		t.Errorf("Expected path %s, got %s", cloudCostPath, u.Path)
+	}
+	q := u.Query()
+	want := `provider:"AWS"+accountID:"123","456"+service:"AmazonRDS"+providerID:"arn:aws:rds:us-east-1:123:db:orders"+label[env]:"prod"+label[stack]:"shop"`
+	if got := q.Get("filter"); got != want {
+		t.Errorf("Expected filter %s, got %s", want, got)
+	}
+	if q.Get("aggregate") != "providerID" || q.Get("accumulate") != "false" {
+		t.Errorf("Unexpected query %s", u.RawQuery)
+	}
+}
+
+func TestCloudCost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != cloudCostPath {
+			t.Errorf("Expected path %s, got %s", cloudCostPath, r.URL.Path)
+		}
+		w.Write([]byte(cloudCostFixture))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	resp, err := client.CloudCost(context.Background(), CloudCostQuery{Window: "1d"})
+	if err != nil {
+		t.Fatalf("CloudCost failed: %v", err)
+	}
+	if len(resp.Data.Sets) != 1 {
+		t.Fatalf("Expected one set, got %d", len(resp.Data.Sets))
+	}
+	cc := resp.Data.Sets[0].CloudCosts["arn:aws:rds:us-east-1:123:db:orders"]
+	if cc.Properties.Service != "AmazonRDS" || cc.Properties.Labels["stack"] != "shop" {
+		t.Errorf("Unexpected properties %+v", cc.Properties)
+	}
+	if cc.ListCost.Cost != 12 || cc.AmortizedNetCost.Cost != 9.5 {
+		t.Errorf("Unexpected costs %+v", cc)
+	}
+}
+
+func TestCloudCostPayloadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"code": 400, "message": "invalid filter"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	_, err = client.CloudCost(context.Background(), CloudCostQuery{Window: "1d"})
+	if !errors.Is(err, ErrBadQuery) {
+		t.Errorf("Expected a bad query error, got %v", err)
+	}
+}
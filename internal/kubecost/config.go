@@ -2,49 +2,260 @@ package kubecost
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
-
-	"gopkg.in/yaml.v3"
 )
 
 const defaultTimeoutDuration = 15 * time.Second
 
+// Request-sizing defaults, Kubecost's production profile.
+const (
+	defaultSizingWindow            = "3d"
+	defaultSizingTargetUtilization = 0.65
+)
+
+// Default deadlines for RPCs whose caller sets none.
+const (
+	defaultListenAddress    = "127.0.0.1:50051"
+	defaultRPCTimeout       = 2 * time.Minute
+	defaultStreamRPCTimeout = 10 * time.Minute
+)
+
 type Config struct {
-	BaseURL       string        `yaml:"baseUrl"`
-	APIToken      string        `yaml:"apiToken"`
-	DefaultWindow string        `yaml:"defaultWindow"` // e.g. "30d"
-	Timeout       time.Duration `yaml:"timeout"`
-	TLSSkipVerify bool          `yaml:"tlsSkipVerify"`
+	Profile  string `yaml:"profile"` // named profile applied from the config file
+	BaseURL  string `yaml:"baseUrl"`
+	APIToken string `yaml:"apiToken"`
+	// Authentication; authType defaults to the method whose fields are set
+	AuthType           string            `yaml:"authType"`     // none, bearer, tokenFile, basic, oauth2
+	APITokenFile       string            `yaml:"apiTokenFile"` // bearer token re-read when the file changes
+	BasicAuthUsername  string            `yaml:"basicAuthUsername"`
+	BasicAuthPassword  string            `yaml:"basicAuthPassword"`
+	OAuth2TokenURL     string            `yaml:"oauth2TokenUrl"` // client credentials token endpoint
+	OAuth2ClientID     string            `yaml:"oauth2ClientId"`
+	OAuth2ClientSecret string            `yaml:"oauth2ClientSecret"`
+	OAuth2Scopes       []string          `yaml:"oauth2Scopes"`
+	Headers            map[string]string `yaml:"headers"`       // extra headers, e.g. X-Scope-OrgID
+	DefaultWindow      string            `yaml:"defaultWindow"` // e.g. "30d"
+	Timeout            time.Duration     `yaml:"timeout"`
+	TLSSkipVerify      bool              `yaml:"tlsSkipVerify"`
+	// TLS toward Kubecost; certificate files are reloaded when they change on disk
+	CAFile         string `yaml:"caFile"`         // PEM bundle of CAs trusted for the Kubecost server
+	ClientCertFile string `yaml:"clientCertFile"` // PEM client certificate for mutual TLS
+	ClientKeyFile  string `yaml:"clientKeyFile"`  // PEM private key for clientCertFile
+	ServerName     string `yaml:"serverName"`     // overrides the name verified in the server certificate
+	TLSMinVersion  string `yaml:"tlsMinVersion"`  // "1.2" (default) or "1.3"
 	// Prediction API specific configuration
 	ClusterID        string `yaml:"clusterId"`
 	DefaultNamespace string `yaml:"defaultNamespace"`
 	PredictionWindow string `yaml:"predictionWindow"` // e.g. "2d" (default for prediction API)
+	// Request-sizing recommendations
+	SizingWindow            string  `yaml:"sizingWindow"`            // usage window recommendations are based on (default: "3d")
+	SizingTargetUtilization float64 `yaml:"sizingTargetUtilization"` // CPU and memory utilization to size requests for (default: 0.65)
+	// Client-side throttling toward Kubecost; zero values disable the limit
+	RateLimit      float64 `yaml:"rateLimit"`      // sustained requests per second
+	RateBurst      int     `yaml:"rateBurst"`      // token bucket size (default: 1 when rateLimit is set)
+	MaxConcurrency int     `yaml:"maxConcurrency"` // maximum requests in flight
+	// Long allocation windows are split into chunks fetched in parallel
+	ChunkWindow        string `yaml:"chunkWindow"`        // e.g. "7d"; empty disables chunking
+	ChunkConcurrency   int    `yaml:"chunkConcurrency"`   // parallel chunk requests of unary RPCs (default: 4)
+	ChunkFailurePolicy string `yaml:"chunkFailurePolicy"` // unary RPCs: "fail" (default) or "partial"
+	// HTTP transport tuning; zero values use the transport defaults
+	DialTimeout           time.Duration `yaml:"dialTimeout"`           // default: 5s
+	TLSHandshakeTimeout   time.Duration `yaml:"tlsHandshakeTimeout"`   // default: 10s
+	ResponseHeaderTimeout time.Duration `yaml:"responseHeaderTimeout"` // default: none
+	IdleConnTimeout       time.Duration `yaml:"idleConnTimeout"`       // default: 90s
+	MaxIdleConns          int           `yaml:"maxIdleConns"`          // default: 100
+	MaxIdleConnsPerHost   int           `yaml:"maxIdleConnsPerHost"`   // default: 10
+	MaxConnsPerHost       int           `yaml:"maxConnsPerHost"`       // default: unlimited
+	DisableHTTP2          bool          `yaml:"disableHttp2"`
+	DisableCompression    bool          `yaml:"disableCompression"` // do not request gzip responses
+	// Proxy for Kubecost requests; HTTPS_PROXY/HTTP_PROXY/NO_PROXY apply when unset
+	ProxyURL string `yaml:"proxyUrl"` // http, https, socks5 or socks5h URL, may carry credentials
+	NoProxy  string `yaml:"noProxy"`  // comma-separated hosts bypassing the proxy, overrides NO_PROXY
+
+	// Logging
+	LogLevel  string `yaml:"logLevel"`  // debug, info (default), warn or error
+	LogFormat string `yaml:"logFormat"` // text (default) or json
+
+	// Tracing; read at startup only
+	TracingExporter string `yaml:"tracingExporter"` // none (default), stdout or otlp
+	OTLPEndpoint    string `yaml:"otlpEndpoint"`    // host:port, OTEL_EXPORTER_OTLP_ENDPOINT applies when empty
+	OTLPInsecure    bool   `yaml:"otlpInsecure"`    // plaintext OTLP, e.g. to a local collector
+
+	// Prometheus metrics listener, e.g. "127.0.0.1:9464"; disabled when empty.
+	// Read at startup only.
+	MetricsAddress string `yaml:"metricsAddress"`
+
+	// Deadlines applied to RPCs the host sends without one, 0 disables; read
+	// at startup only
+	RPCTimeout       time.Duration `yaml:"rpcTimeout"`       // unary RPCs (default: 2m)
+	StreamRPCTimeout time.Duration `yaml:"streamRpcTimeout"` // streaming RPCs (default: 10m)
+
+	// The plugin's own gRPC server; read at startup only. Without a server
+	// certificate it serves plaintext, which is only safe on loopback.
+	ListenAddress      string `yaml:"listenAddress"`      // default: 127.0.0.1:50051
+	ServerCertFile     string `yaml:"serverCertFile"`     // PEM certificate enabling TLS, reloaded when it changes on disk
+	ServerKeyFile      string `yaml:"serverKeyFile"`      // PEM private key for serverCertFile
+	ServerClientCAFile string `yaml:"serverClientCaFile"` // PEM CAs that must have signed client certificates (mTLS)
+	ServerAuthToken    string `yaml:"serverAuthToken"`    // callers must send "authorization: Bearer <token>" metadata
+
+	// locs records where each key was set, for validation messages
+	locs map[string]location
 }
 
+// LoadOptions selects the configuration layers beyond defaults and environment.
+type LoadOptions struct {
+	Path      string   // YAML config file; a missing file is ignored
+	Profile   string   // profile to apply; overrides KUBECOST_PROFILE and the file's "profile" key
+	Overrides []string // key=value settings from the command line, applied last
+}
+
+// LoadConfigFromEnvOrFile reads the configuration from the optional YAML file at
+// path and KUBECOST_* environment variables, which take precedence over the
+// file. Unknown keys and values that do not parse are reported together as a
+// *ValidationError; use Validate or LoadValidatedConfig to check the resulting
+// settings as well.
 func LoadConfigFromEnvOrFile(path string) (Config, error) {
-	cfg := Config{
-		BaseURL:          os.Getenv("KUBECOST_BASE_URL"),
-		APIToken:         os.Getenv("KUBECOST_API_TOKEN"),
-		DefaultWindow:    getenvDefault("KUBECOST_DEFAULT_WINDOW", "30d"),
-		Timeout:          getenvDuration("KUBECOST_TIMEOUT", defaultTimeoutDuration),
-		TLSSkipVerify:    os.Getenv("KUBECOST_TLS_SKIP_VERIFY") == "true",
-		ClusterID:        os.Getenv("KUBECOST_CLUSTER_ID"),
-		DefaultNamespace: getenvDefault("KUBECOST_DEFAULT_NAMESPACE", "default"),
-		PredictionWindow: getenvDefault("KUBECOST_PREDICTION_WINDOW", "2d"),
+	cfg, problems, err := loadConfig(LoadOptions{Path: path})
+	if err != nil {
+		return cfg, err
+	}
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Problems: problems}
 	}
-	if path != "" {
-		b, err := os.ReadFile(path)
+	return cfg, nil
+}
+
+// LoadValidatedConfig loads the configuration layers selected by opts and
+// validates the result, reporting parse and validation problems at once.
+func LoadValidatedConfig(opts LoadOptions) (Config, error) {
+	cfg, problems, err := loadConfig(opts)
+	if err != nil {
+		return cfg, err
+	}
+	problems = append(problems, cfg.problems()...)
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// loadConfig layers, from lowest to highest precedence: defaults, the config
+// file's top-level keys, the selected profile, environment variables and
+// command-line overrides. Per-request fields such as a query's time range or
+// cluster ID override all of these when the RPC is served.
+func loadConfig(opts LoadOptions) (Config, []Problem, error) {
+	cfg := defaultConfig()
+	var problems []Problem
+
+	profile, selectedBy := opts.Profile, location{source: "-profile"}
+	if profile == "" {
+		profile, selectedBy = os.Getenv("KUBECOST_PROFILE"), location{source: "KUBECOST_PROFILE"}
+	}
+	if opts.Path != "" {
+		b, err := os.ReadFile(opts.Path)
 		if err != nil {
 			// If file doesn't exist, just use environment/default values
 			if !errors.Is(err, os.ErrNotExist) {
-				return cfg, err
+				return cfg, nil, err
 			}
-		} else {
-			_ = yaml.Unmarshal(b, &cfg)
+			b = nil
 		}
+		problems = append(problems, decodeConfigFile(opts.Path, b, profile, selectedBy, &cfg)...)
+	} else if profile != "" {
+		problems = append(problems, Problem{
+			Source: selectedBy.source, Field: "profile",
+			Message: fmt.Sprintf("profile %q selected but no config file is set (KUBECOST_CONFIG)", profile),
+		})
+	}
+
+	problems = append(problems, applyEnv(&cfg)...)
+	if opts.Profile != "" {
+		cfg.Profile = opts.Profile
+	}
+	problems = append(problems, applyOverrides(&cfg, opts.Overrides)...)
+	return cfg, problems, nil
+}
+
+// defaultConfig returns the settings used when no layer sets a key.
+func defaultConfig() Config {
+	return Config{
+		DefaultWindow:           "30d",
+		Timeout:                 defaultTimeoutDuration,
+		DefaultNamespace:        "default",
+		PredictionWindow:        "2d",
+		SizingWindow:            defaultSizingWindow,
+		SizingTargetUtilization: defaultSizingTargetUtilization,
+		ChunkConcurrency:        defaultChunkConcurrency,
+		ChunkFailurePolicy:      ChunkPolicyFail,
+		LogLevel:                "info",
+		LogFormat:               "text",
+		TracingExporter:         "none",
+		RPCTimeout:              defaultRPCTimeout,
+		StreamRPCTimeout:        defaultStreamRPCTimeout,
+		ListenAddress:           defaultListenAddress,
+		locs:                    map[string]location{},
+	}
+}
+
+// envConfig reads every KUBECOST_* environment variable; applyEnv copies the
+// ones that are set.
+func envConfig() Config {
+	return Config{
+		Profile:                 os.Getenv("KUBECOST_PROFILE"),
+		BaseURL:                 os.Getenv("KUBECOST_BASE_URL"),
+		APIToken:                os.Getenv("KUBECOST_API_TOKEN"),
+		AuthType:                os.Getenv("KUBECOST_AUTH_TYPE"),
+		APITokenFile:            os.Getenv("KUBECOST_API_TOKEN_FILE"),
+		BasicAuthUsername:       os.Getenv("KUBECOST_BASIC_AUTH_USERNAME"),
+		BasicAuthPassword:       os.Getenv("KUBECOST_BASIC_AUTH_PASSWORD"),
+		OAuth2TokenURL:          os.Getenv("KUBECOST_OAUTH2_TOKEN_URL"),
+		OAuth2ClientID:          os.Getenv("KUBECOST_OAUTH2_CLIENT_ID"),
+		OAuth2ClientSecret:      os.Getenv("KUBECOST_OAUTH2_CLIENT_SECRET"),
+		OAuth2Scopes:            getenvList("KUBECOST_OAUTH2_SCOPES"),
+		Headers:                 getenvMap("KUBECOST_HEADERS"),
+		DefaultWindow:           getenvDefault("KUBECOST_DEFAULT_WINDOW", "30d"),
+		Timeout:                 getenvDuration("KUBECOST_TIMEOUT", defaultTimeoutDuration),
+		TLSSkipVerify:           os.Getenv("KUBECOST_TLS_SKIP_VERIFY") == "true",
+		ClusterID:               os.Getenv("KUBECOST_CLUSTER_ID"),
+		DefaultNamespace:        getenvDefault("KUBECOST_DEFAULT_NAMESPACE", "default"),
+		PredictionWindow:        getenvDefault("KUBECOST_PREDICTION_WINDOW", "2d"),
+		SizingWindow:            getenvDefault("KUBECOST_SIZING_WINDOW", defaultSizingWindow),
+		SizingTargetUtilization: getenvFloat("KUBECOST_SIZING_TARGET_UTILIZATION", defaultSizingTargetUtilization),
+		RateLimit:               getenvFloat("KUBECOST_RATE_LIMIT", 0),
+		RateBurst:               getenvInt("KUBECOST_RATE_BURST", 0),
+		MaxConcurrency:          getenvInt("KUBECOST_MAX_CONCURRENCY", 0),
+		ChunkWindow:             os.Getenv("KUBECOST_CHUNK_WINDOW"),
+		ChunkConcurrency:        getenvInt("KUBECOST_CHUNK_CONCURRENCY", defaultChunkConcurrency),
+		ChunkFailurePolicy:      getenvDefault("KUBECOST_CHUNK_FAILURE_POLICY", ChunkPolicyFail),
+		DialTimeout:             getenvDuration("KUBECOST_DIAL_TIMEOUT", 0),
+		TLSHandshakeTimeout:     getenvDuration("KUBECOST_TLS_HANDSHAKE_TIMEOUT", 0),
+		ResponseHeaderTimeout:   getenvDuration("KUBECOST_RESPONSE_HEADER_TIMEOUT", 0),
+		IdleConnTimeout:         getenvDuration("KUBECOST_IDLE_CONN_TIMEOUT", 0),
+		MaxIdleConns:            getenvInt("KUBECOST_MAX_IDLE_CONNS", 0),
+		MaxIdleConnsPerHost:     getenvInt("KUBECOST_MAX_IDLE_CONNS_PER_HOST", 0),
+		MaxConnsPerHost:         getenvInt("KUBECOST_MAX_CONNS_PER_HOST", 0),
+		DisableHTTP2:            os.Getenv("KUBECOST_DISABLE_HTTP2") == "true",
+		DisableCompression:      os.Getenv("KUBECOST_DISABLE_COMPRESSION") == "true",
+		ProxyURL:                os.Getenv("KUBECOST_PROXY_URL"),
+		NoProxy:                 os.Getenv("KUBECOST_NO_PROXY"),
+		LogLevel:                getenvDefault("KUBECOST_LOG_LEVEL", "info"),
+		LogFormat:               getenvDefault("KUBECOST_LOG_FORMAT", "text"),
+		TracingExporter:         getenvDefault("KUBECOST_TRACING_EXPORTER", "none"),
+		OTLPEndpoint:            os.Getenv("KUBECOST_OTLP_ENDPOINT"),
+		OTLPInsecure:            os.Getenv("KUBECOST_OTLP_INSECURE") == "true",
+		MetricsAddress:          os.Getenv("KUBECOST_METRICS_ADDRESS"),
+		RPCTimeout:              getenvDuration("KUBECOST_RPC_TIMEOUT", defaultRPCTimeout),
+		StreamRPCTimeout:        getenvDuration("KUBECOST_STREAM_RPC_TIMEOUT", defaultStreamRPCTimeout),
+		ListenAddress:           getenvDefault("KUBECOST_LISTEN_ADDRESS", defaultListenAddress),
+		ServerCertFile:          os.Getenv("KUBECOST_SERVER_CERT_FILE"),
+		ServerKeyFile:           os.Getenv("KUBECOST_SERVER_KEY_FILE"),
+		ServerClientCAFile:      os.Getenv("KUBECOST_SERVER_CLIENT_CA_FILE"),
+		ServerAuthToken:         os.Getenv("KUBECOST_SERVER_AUTH_TOKEN"),
 	}
-	return cfg, nil
 }
 
 func getenvDefault(k, def string) string {
@@ -54,7 +265,6 @@ func getenvDefault(k, def string) string {
 	return def
 }
 
-//nolint:unparam // Function kept generic for potential future use
 func getenvDuration(k string, def time.Duration) time.Duration {
 	if v := os.Getenv(k); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
@@ -63,3 +273,50 @@ func getenvDuration(k string, def time.Duration) time.Duration {
 	}
 	return def
 }
+
+//nolint:unparam // Function kept generic for potential future use
+func getenvFloat(k string, def float64) float64 {
+	if v := os.Getenv(k); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+//nolint:unparam // Function kept generic for potential future use
+func getenvInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return def
+}
+
+// getenvList parses a comma-separated list.
+func getenvList(k string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(k), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// getenvMap parses comma-separated key=value pairs.
+func getenvMap(k string) map[string]string {
+	var out map[string]string
+	for _, kv := range getenvList(k) {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = map[string]string{}
+		}
+		out[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return out
+}
@@ -97,7 +97,7 @@ tlsSkipVerify: false
 	}
 	tmpFile.Close()
 
-	// Set environment variables (should be overridden by file)
+	// Set environment variables (take precedence over the file)
 	t.Setenv("KUBECOST_BASE_URL", "http://env-test:9090")
 	t.Setenv("KUBECOST_API_TOKEN", "env-token")
 	defer func() {
@@ -110,15 +110,16 @@ tlsSkipVerify: false
 		t.Fatalf("LoadConfigFromEnvOrFile failed: %v", err)
 	}
 
-	// File values should override environment values
-	if cfg.BaseURL != "http://file-test:9090" {
-		t.Errorf("Expected BaseURL %s, got %s", "http://file-test:9090", cfg.BaseURL)
+	// Environment values should override file values
+	if cfg.BaseURL != "http://env-test:9090" {
+		t.Errorf("Expected BaseURL %s, got %s", "http://env-test:9090", cfg.BaseURL)
 	}
 
-	if cfg.APIToken != "file-token" {
-		t.Errorf("Expected APIToken %s, got %s", "file-token", cfg.APIToken)
+	if cfg.APIToken != "env-token" {
+		t.Errorf("Expected APIToken %s, got %s", "env-token", cfg.APIToken)
 	}
 
+	// Keys not set in the environment come from the file
 	if cfg.DefaultWindow != "14d" {
 		t.Errorf("Expected DefaultWindow %s, got %s", "14d", cfg.DefaultWindow)
 	}
@@ -0,0 +1,240 @@
+package kubecost
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey holds named profiles in the config file; each one is a partial
+// configuration applied over the top-level keys.
+const profilesKey = "profiles"
+
+// overrideSource names command-line overrides in validation messages.
+const overrideSource = "-set"
+
+// configKeys indexes Config's fields by YAML key.
+var configKeys = func() map[string]int {
+	t := reflect.TypeOf(Config{})
+	keys := make(map[string]int, t.NumField())
+	for i := range t.NumField() {
+		if key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); key != "" && key != "-" {
+			keys[key] = i
+		}
+	}
+	return keys
+}()
+
+// envReference matches ${VAR} and ${VAR:-default} in config file values.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+var yamlLineError = regexp.MustCompile(`line (\d+): (.*)`)
+
+// decodeConfigFile decodes the YAML file at path into cfg: the top-level keys
+// first, then the selected profile. profile, when not empty, was chosen on the
+// command line or with KUBECOST_PROFILE and wins over the file's own "profile"
+// key; selectedBy names where it came from.
+func decodeConfigFile(path string, b []byte, profile string, selectedBy location, cfg *Config) []Problem {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return yamlProblems(path, err, nil)
+	}
+	if len(root.Content) == 0 {
+		if profile != "" {
+			return []Problem{unknownProfile(profile, selectedBy, nil)}
+		}
+		return nil
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return []Problem{{Source: path, Line: doc.Line, Message: "expected a mapping of configuration keys"}}
+	}
+
+	// Only the top level and the selected profile are interpolated, so unused
+	// profiles may reference variables that are not set
+	var problems []Problem
+	var profiles *yaml.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		if key.Value == profilesKey {
+			profiles = value
+			continue
+		}
+		problems = append(problems, interpolate(path, key.Value, value)...)
+	}
+	problems = append(problems, decodeLayer(path, doc, cfg, true)...)
+
+	if profile == "" {
+		profile = cfg.Profile
+		selectedBy = cfg.locs["profile"]
+	}
+	if profile == "" {
+		return problems
+	}
+	cfg.Profile = profile
+	if profiles != nil && profiles.Kind != yaml.MappingNode {
+		return append(problems, Problem{Source: path, Line: profiles.Line, Field: profilesKey, Message: "expected a mapping of profile names"})
+	}
+
+	var names []string
+	if profiles != nil {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			name, body := profiles.Content[i], profiles.Content[i+1]
+			names = append(names, name.Value)
+			if name.Value != profile {
+				continue
+			}
+			if body.Kind != yaml.MappingNode {
+				return append(problems, Problem{
+					Source: path, Line: body.Line, Field: profilesKey + "." + profile,
+					Message: "expected a mapping of configuration keys",
+				})
+			}
+			problems = append(problems, interpolate(path, "", body)...)
+			return append(problems, decodeLayer(path, body, cfg, false)...)
+		}
+	}
+	return append(problems, unknownProfile(profile, selectedBy, names))
+}
+
+func unknownProfile(profile string, selectedBy location, names []string) Problem {
+	msg := fmt.Sprintf("unknown profile %q", profile)
+	if len(names) > 0 {
+		slices.Sort(names)
+		msg += " (available: " + strings.Join(names, ", ") + ")"
+	}
+	return Problem{Source: selectedBy.source, Line: selectedBy.line, Field: "profile", Message: msg}
+}
+
+// decodeLayer decodes the mapping m into cfg, recording where each key was
+// set. Keys Config does not declare are reported rather than ignored; the
+// top level may also carry the profiles section, profiles may not.
+func decodeLayer(source string, m *yaml.Node, cfg *Config, topLevel bool) []Problem {
+	var problems []Problem
+	keysByLine := map[int]string{}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := m.Content[i], m.Content[i+1]
+		switch _, known := configKeys[key.Value]; {
+		case topLevel && key.Value == profilesKey:
+			continue
+		case !topLevel && (key.Value == "profile" || key.Value == profilesKey):
+			problems = append(problems, Problem{Source: source, Line: key.Line, Field: key.Value, Message: "not allowed here, select profiles with -profile or KUBECOST_PROFILE"})
+			continue
+		case !known:
+			problems = append(problems, Problem{Source: source, Line: key.Line, Field: key.Value, Message: "unknown key"})
+			continue
+		}
+		cfg.locs[key.Value] = location{source: source, line: key.Line}
+		keysByLine[key.Line] = key.Value
+		keysByLine[value.Line] = key.Value
+	}
+
+	// Decoding a node ignores unknown keys, which were reported above
+	profile := cfg.Profile
+	if err := m.Decode(cfg); err != nil {
+		problems = append(problems, yamlProblems(source, err, keysByLine)...)
+	}
+	if !topLevel {
+		cfg.Profile = profile
+	}
+	return problems
+}
+
+// interpolate expands ${VAR} and ${VAR:-default} references in the scalar
+// values under n. References to unset variables without a default are
+// reported; values expanded from plain scalars are re-typed so that, for
+// example, "rateBurst: ${BURST}" decodes as an integer.
+func interpolate(source, key string, n *yaml.Node) []Problem {
+	var problems []Problem
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			problems = append(problems, interpolate(source, n.Content[i].Value, n.Content[i+1])...)
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, c := range n.Content {
+			problems = append(problems, interpolate(source, key, c)...)
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(n.Value, "${") {
+			return nil
+		}
+		n.Value = envReference.ReplaceAllStringFunc(n.Value, func(ref string) string {
+			m := envReference.FindStringSubmatch(ref)
+			if v, ok := os.LookupEnv(m[1]); ok && v != "" {
+				return v
+			}
+			if strings.Contains(ref, ":-") {
+				return m[2]
+			}
+			problems = append(problems, Problem{
+				Source: source, Line: n.Line, Field: key,
+				Message: fmt.Sprintf("environment variable %s is not set", m[1]),
+			})
+			return ""
+		})
+		if n.Style == 0 {
+			n.Tag = ""
+		}
+	case yaml.AliasNode:
+	}
+	return problems
+}
+
+// applyOverrides applies key=value settings from the command line. Values are
+// parsed as YAML, so lists are written as [a, b].
+func applyOverrides(cfg *Config, overrides []string) []Problem {
+	var problems []Problem
+	for _, o := range overrides {
+		key, raw, ok := strings.Cut(o, "=")
+		if !ok || key == "" {
+			problems = append(problems, Problem{Source: overrideSource, Message: fmt.Sprintf("%q is not key=value", o)})
+			continue
+		}
+		value := &yaml.Node{Kind: yaml.ScalarNode, Value: raw}
+		var parsed yaml.Node
+		if err := yaml.Unmarshal([]byte(raw), &parsed); err == nil && len(parsed.Content) > 0 {
+			value = parsed.Content[0]
+		}
+		m := &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: key}, value},
+		}
+		for _, p := range decodeLayer(overrideSource, m, cfg, false) {
+			p.Line = 0
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+// yamlProblems converts yaml.v3 errors, which carry "line N:" prefixes, into
+// problems.
+func yamlProblems(path string, err error, keysByLine map[int]string) []Problem {
+	var msgs []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+
+	problems := make([]Problem, 0, len(msgs))
+	for _, msg := range msgs {
+		p := Problem{Source: path, Message: strings.TrimPrefix(msg, "yaml: ")}
+		if m := yamlLineError.FindStringSubmatch(msg); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			p.Message = m[2]
+			p.Field = keysByLine[p.Line]
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
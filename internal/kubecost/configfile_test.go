@@ -0,0 +1,118 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profilesConfig = `baseUrl: http://kubecost.dev:9090
+timeout: 10s
+profile: dev
+headers:
+  X-Scope-OrgID: ${KUBECOST_TENANT:-shared}
+profiles:
+  dev:
+    rateLimit: 5
+  prod:
+    baseUrl: https://kubecost.prod.example.com
+    apiToken: ${PROD_TOKEN}
+    rateBurst: ${PROD_BURST}
+    timeout: 30s
+`
+
+func writeProfilesConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, []byte(profilesConfig))
+	return path
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeProfilesConfig(t)
+	t.Setenv("KUBECOST_PROFILE", "prod")
+	t.Setenv("PROD_TOKEN", "prod-token")
+	t.Setenv("PROD_BURST", "8")
+	t.Setenv("KUBECOST_TIMEOUT", "45s")
+
+	cfg, problems, err := loadConfig(LoadOptions{Path: path, Overrides: []string{"timeout=1m", "oauth2Scopes=[read, cost]"}})
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("loadConfig failed: %v %v", err, problems)
+	}
+
+	if cfg.Profile != "prod" {
+		t.Errorf("Expected KUBECOST_PROFILE to select prod over the file's default, got %q", cfg.Profile)
+	}
+	if cfg.BaseURL != "https://kubecost.prod.example.com" {
+		t.Errorf("Expected profile baseUrl, got %q", cfg.BaseURL)
+	}
+	if cfg.APIToken != "prod-token" || cfg.RateBurst != 8 {
+		t.Errorf("Expected interpolated token and burst, got %q/%d", cfg.APIToken, cfg.RateBurst)
+	}
+	if cfg.Headers["X-Scope-OrgID"] != "shared" {
+		t.Errorf("Expected interpolation default, got %q", cfg.Headers["X-Scope-OrgID"])
+	}
+	if cfg.Timeout != time.Minute {
+		t.Errorf("Expected -set timeout to win over env, profile and file, got %v", cfg.Timeout)
+	}
+	if len(cfg.OAuth2Scopes) != 2 {
+		t.Errorf("Expected YAML list override, got %v", cfg.OAuth2Scopes)
+	}
+	if cfg.DefaultWindow != "30d" {
+		t.Errorf("Expected default window, got %q", cfg.DefaultWindow)
+	}
+
+	cfg, _, _ = loadConfig(LoadOptions{Path: path})
+	if cfg.Timeout != 45*time.Second {
+		t.Errorf("Expected env timeout over profile and file, got %v", cfg.Timeout)
+	}
+}
+
+func TestLoadConfigProfileSelection(t *testing.T) {
+	path := writeProfilesConfig(t)
+
+	cfg, problems, _ := loadConfig(LoadOptions{Path: path})
+	if len(problems) > 0 || cfg.Profile != "dev" || cfg.RateLimit != 5 {
+		t.Errorf("Expected the file's default dev profile, got %q rateLimit=%v %v", cfg.Profile, cfg.RateLimit, problems)
+	}
+
+	t.Setenv("KUBECOST_PROFILE", "prod")
+	_, problems, _ = loadConfig(LoadOptions{Path: path, Profile: "staging"})
+	if len(problems) == 0 || problems[0].Source != "-profile" ||
+		!strings.Contains(problems[0].Message, "available: dev, prod") {
+		t.Errorf("Expected unknown profile from -profile, got %v", problems)
+	}
+
+	_, problems, _ = loadConfig(LoadOptions{Profile: "dev"})
+	if len(problems) != 1 || problems[0].Field != "profile" {
+		t.Errorf("Expected profile without config file to be reported, got %v", problems)
+	}
+}
+
+func TestLoadConfigLayerProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, []byte(`baseUrl: ${MISSING_KUBECOST_URL}
+profiles:
+  prod:
+    profile: dev
+    bogus: 1
+`))
+
+	_, problems, _ := loadConfig(LoadOptions{Path: path, Profile: "prod", Overrides: []string{"noEquals", "rateBurst=many"}})
+	expected := []string{
+		path + ":1: baseUrl: environment variable MISSING_KUBECOST_URL is not set",
+		path + ":4: profile: not allowed here, select profiles with -profile or KUBECOST_PROFILE",
+		path + ":5: bogus: unknown key",
+		"-set: \"noEquals\" is not key=value",
+		"-set: rateBurst: cannot unmarshal !!str `many` into int",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, want := range expected {
+		if got := problems[i].String(); got != want {
+			t.Errorf("problem %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package kubecost
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorKind classifies failures talking to Kubecost.
+type ErrorKind int
+
+const (
+	// KindUnknown is an unclassified failure.
+	KindUnknown ErrorKind = iota
+	// KindUnauthorized means Kubecost rejected the credentials (HTTP 401).
+	KindUnauthorized
+	// KindForbidden means the credentials lack access (HTTP 403).
+	KindForbidden
+	// KindNotFound means the endpoint or object does not exist (HTTP 404).
+	KindNotFound
+	// KindRateLimited means Kubecost or a proxy throttled the request (HTTP 429).
+	KindRateLimited
+	// KindUnavailable means Kubecost could not be reached or failed (HTTP 5xx, network errors).
+	KindUnavailable
+	// KindBadQuery means Kubecost rejected the query parameters (HTTP 400/422).
+	KindBadQuery
+	// KindDecode means the response could not be decoded.
+	KindDecode
+)
+
+// Sentinel errors matching each kind with errors.Is.
+var (
+	ErrUnauthorized = errors.New("kubecost unauthorized")
+	ErrForbidden    = errors.New("kubecost forbidden")
+	ErrNotFound     = errors.New("kubecost not found")
+	ErrRateLimited  = errors.New("kubecost rate limited")
+	ErrUnavailable  = errors.New("kubecost unavailable")
+	ErrBadQuery     = errors.New("kubecost bad query")
+	ErrDecode       = errors.New("kubecost decode failure")
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindNotFound:
+		return "not found"
+	case KindRateLimited:
+		return "rate limited"
+	case KindUnavailable:
+		return "unavailable"
+	case KindBadQuery:
+		return "bad query"
+	case KindDecode:
+		return "decode failure"
+	case KindUnknown:
+	}
+	return "error"
+}
+
+func (k ErrorKind) sentinel() error {
+	switch k {
+	case KindUnauthorized:
+		return ErrUnauthorized
+	case KindForbidden:
+		return ErrForbidden
+	case KindNotFound:
+		return ErrNotFound
+	case KindRateLimited:
+		return ErrRateLimited
+	case KindUnavailable:
+		return ErrUnavailable
+	case KindBadQuery:
+		return ErrBadQuery
+	case KindDecode:
+		return ErrDecode
+	case KindUnknown:
+	}
+	return nil
+}
+
+// APIError describes a failed Kubecost API call.
+type APIError struct {
+	Kind       ErrorKind
+	Endpoint   string        // API path, e.g. "/model/allocation"
+	StatusCode int           // HTTP status, when the server responded
+	Code       int           // Kubecost payload code, when the body carried "code" != 200
+	Message    string        // Kubecost message or (capped) error body
+	RetryAfter time.Duration // from the Retry-After header, when present
+	Err        error         // underlying transport or decode error
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString("kubecost ")
+	b.WriteString(e.Kind.String())
+	if e.Endpoint != "" {
+		b.WriteString(" ")
+		b.WriteString(e.Endpoint)
+	}
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, ": status=%d", e.StatusCode)
+	}
+	if e.Code != 0 {
+		fmt.Fprintf(&b, ": code=%d", e.Code)
+	}
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error for e's kind.
+func (e *APIError) Is(target error) bool {
+	s := e.Kind.sentinel()
+	return s != nil && target == s
+}
+
+// kindForStatus maps an HTTP status (or Kubecost payload code) to an ErrorKind.
+func kindForStatus(code int) ErrorKind {
+	switch {
+	case code == http.StatusUnauthorized:
+		return KindUnauthorized
+	case code == http.StatusForbidden:
+		return KindForbidden
+	case code == http.StatusNotFound:
+		return KindNotFound
+	case code == http.StatusTooManyRequests:
+		return KindRateLimited
+	case code == http.StatusBadRequest || code == http.StatusUnprocessableEntity:
+		return KindBadQuery
+	case code >= http.StatusInternalServerError:
+		return KindUnavailable
+	}
+	return KindUnknown
+}
+
+// statusError builds an APIError from a non-success HTTP response.
+func statusError(endpoint string, resp *http.Response, body string) *APIError {
+	return &APIError{
+		Kind:       kindForStatus(resp.StatusCode),
+		Endpoint:   endpoint,
+		StatusCode: resp.StatusCode,
+		Message:    body,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// payloadError builds an APIError from a Kubecost response whose "code" is not 200.
+func payloadError(endpoint string, code int, message string) *APIError {
+	kind := kindForStatus(code)
+	if kind == KindUnknown {
+		kind = KindUnavailable
+	}
+	return &APIError{Kind: kind, Endpoint: endpoint, Code: code, Message: message}
+}
+
+// transportError wraps a failure to obtain a response from Kubecost. Errors that
+// are already classified, such as a failed OAuth2 token fetch, are kept as is.
+func transportError(endpoint string, err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &APIError{Kind: KindUnavailable, Endpoint: endpoint, Err: err}
+}
+
+// decodeError wraps a failure to decode a Kubecost response.
+func decodeError(endpoint string, err error) *APIError {
+	return &APIError{Kind: KindDecode, Endpoint: endpoint, Err: err}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKindForStatus(t *testing.T) {
+	testCases := map[int]ErrorKind{
+		http.StatusBadRequest:          KindBadQuery,
+		http.StatusUnauthorized:        KindUnauthorized,
+		http.StatusForbidden:           KindForbidden,
+		http.StatusNotFound:            KindNotFound,
+		http.StatusUnprocessableEntity: KindBadQuery,
+		http.StatusTooManyRequests:     KindRateLimited,
+		http.StatusInternalServerError: KindUnavailable,
+		http.StatusBadGateway:          KindUnavailable,
+		http.StatusConflict:            KindUnknown,
+	}
+	for code, want := range testCases {
+		if got := kindForStatus(code); got != want {
+			t.Errorf("kindForStatus(%d): expected %v, got %v", code, want, got)
+		}
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	err := error(&APIError{Kind: KindForbidden, Endpoint: allocationPath, StatusCode: http.StatusForbidden})
+	if !errors.Is(err, ErrForbidden) {
+		t.Error("Expected errors.Is to match ErrForbidden")
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		t.Error("Expected errors.Is not to match ErrUnauthorized")
+	}
+
+	wrapped := transportError(allocationPath, context.DeadlineExceeded)
+	if !errors.Is(wrapped, context.DeadlineExceeded) || !errors.Is(wrapped, ErrUnavailable) {
+		t.Error("Expected transport error to match both its cause and ErrUnavailable")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("7"); got != 7*time.Second {
+		t.Errorf("Expected 7s, got %v", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("Expected 0 for empty header, got %v", got)
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("Expected positive duration up to 1m, got %v", got)
+	}
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `denied`, ErrUnauthorized},
+		{"rate limited", http.StatusTooManyRequests, `slow down`, ErrRateLimited},
+		{"unavailable", http.StatusServiceUnavailable, `down`, ErrUnavailable},
+		{"payload code", http.StatusOK, `{"code": 400, "message": "bad filter"}`, ErrBadQuery},
+		{"decode", http.StatusOK, `{"code": 200, "data": [`, ErrDecode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			_, err = client.GetDetailedAllocation(context.Background(), AllocationQuery{Window: "1d"})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Expected %v, got %v", tc.want, err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *APIError, got %T", err)
+			}
+			if apiErr.Endpoint != allocationPath {
+				t.Errorf("Expected endpoint %s, got %s", allocationPath, apiErr.Endpoint)
+			}
+			if tc.status == http.StatusTooManyRequests && apiErr.RetryAfter != 3*time.Second {
+				t.Errorf("Expected RetryAfter 3s, got %v", apiErr.RetryAfter)
+			}
+			if tc.status >= http.StatusBadRequest && !strings.Contains(err.Error(), tc.body) {
+				t.Errorf("Expected error to include body %q, got %v", tc.body, err)
+			}
+		})
+	}
+}
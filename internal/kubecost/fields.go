@@ -0,0 +1,200 @@
+package kubecost
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/tracing"
+)
+
+// configField documents one configuration key: the environment variable that
+// sets it and what the plugin manifest and JSON Schema publish about it.
+type configField struct {
+	key, env    string
+	description string
+	enum        []string
+	required    bool
+	secret      bool
+}
+
+// configFields lists every configuration key in manifest order. A test checks
+// that it covers each YAML key of Config.
+var configFields = []configField{
+	{key: "profile", env: "KUBECOST_PROFILE", description: "Named profile from the config file to apply over the top-level keys"},
+	{key: "baseUrl", env: "KUBECOST_BASE_URL", description: "Kubecost API base URL", required: true},
+	{key: "apiToken", env: "KUBECOST_API_TOKEN", description: "Kubecost API token for bearer authentication", secret: true},
+	{
+		key: "authType", env: "KUBECOST_AUTH_TYPE",
+		description: "Authentication method; inferred from the credentials set when empty",
+		enum:        []string{AuthNone, AuthBearer, AuthTokenFile, AuthBasic, AuthOAuth2},
+	},
+	{key: "apiTokenFile", env: "KUBECOST_API_TOKEN_FILE", description: "File holding a bearer token, re-read when it changes"},
+	{key: "basicAuthUsername", env: "KUBECOST_BASIC_AUTH_USERNAME", description: "HTTP basic auth username"},
+	{key: "basicAuthPassword", env: "KUBECOST_BASIC_AUTH_PASSWORD", description: "HTTP basic auth password", secret: true},
+	{key: "oauth2TokenUrl", env: "KUBECOST_OAUTH2_TOKEN_URL", description: "OAuth2 client credentials token endpoint"},
+	{key: "oauth2ClientId", env: "KUBECOST_OAUTH2_CLIENT_ID", description: "OAuth2 client ID"},
+	{key: "oauth2ClientSecret", env: "KUBECOST_OAUTH2_CLIENT_SECRET", description: "OAuth2 client secret", secret: true},
+	{key: "oauth2Scopes", env: "KUBECOST_OAUTH2_SCOPES", description: "OAuth2 scopes to request (comma-separated in the environment)"},
+	{key: "headers", env: "KUBECOST_HEADERS", description: "Extra request headers such as X-Scope-OrgID (key=value pairs in the environment)"},
+	{key: "defaultWindow", env: "KUBECOST_DEFAULT_WINDOW", description: "Default time window for queries without a time range (e.g., 30d)"},
+	{key: "timeout", env: "KUBECOST_TIMEOUT", description: "Time limit for each Kubecost query including all its chunks (e.g., 15s); a shorter RPC deadline wins, streamed queries use the RPC deadline"},
+	{key: "tlsSkipVerify", env: "KUBECOST_TLS_SKIP_VERIFY", description: "Skip TLS certificate verification"},
+	{key: "caFile", env: "KUBECOST_CA_FILE", description: "PEM bundle of CAs trusted for the Kubecost server"},
+	{key: "clientCertFile", env: "KUBECOST_CLIENT_CERT_FILE", description: "PEM client certificate for mutual TLS"},
+	{key: "clientKeyFile", env: "KUBECOST_CLIENT_KEY_FILE", description: "PEM private key for clientCertFile"},
+	{key: "serverName", env: "KUBECOST_TLS_SERVER_NAME", description: "Name verified in the Kubecost server certificate"},
+	{key: "tlsMinVersion", env: "KUBECOST_TLS_MIN_VERSION", description: "Minimum TLS version", enum: []string{"1.2", "1.3"}},
+	{key: "clusterId", env: "KUBECOST_CLUSTER_ID", description: "Cluster ID for the prediction API"},
+	{key: "defaultNamespace", env: "KUBECOST_DEFAULT_NAMESPACE", description: "Namespace for prediction workloads that do not set one"},
+	{key: "predictionWindow", env: "KUBECOST_PREDICTION_WINDOW", description: "Usage window the prediction API bases estimates on"},
+	{key: "sizingWindow", env: "KUBECOST_SIZING_WINDOW", description: "Usage window request-sizing recommendations are based on"},
+	{
+		key: "sizingTargetUtilization", env: "KUBECOST_SIZING_TARGET_UTILIZATION",
+		description: "CPU and memory utilization, 0-1, that recommended requests are sized for; 0 leaves it to Kubecost",
+	},
+	{key: "rateLimit", env: "KUBECOST_RATE_LIMIT", description: "Sustained requests per second toward Kubecost, 0 disables"},
+	{key: "rateBurst", env: "KUBECOST_RATE_BURST", description: "Token bucket size, 1 when rateLimit is set and this is 0"},
+	{key: "maxConcurrency", env: "KUBECOST_MAX_CONCURRENCY", description: "Maximum Kubecost requests in flight, 0 disables"},
+	{key: "chunkWindow", env: "KUBECOST_CHUNK_WINDOW", description: "Split longer allocation windows into chunks of this size (e.g., 7d)"},
+	{key: "chunkConcurrency", env: "KUBECOST_CHUNK_CONCURRENCY", description: "Parallel chunk requests of unary RPCs; streaming RPCs fetch chunks one at a time"},
+	{
+		key: "chunkFailurePolicy", env: "KUBECOST_CHUNK_FAILURE_POLICY",
+		description: "Whether a failed chunk fails a unary query or returns the other chunks; streams always fail",
+		enum:        []string{ChunkPolicyFail, ChunkPolicyPartial},
+	},
+	{key: "dialTimeout", env: "KUBECOST_DIAL_TIMEOUT", description: "TCP connect timeout, 5s when 0"},
+	{key: "tlsHandshakeTimeout", env: "KUBECOST_TLS_HANDSHAKE_TIMEOUT", description: "TLS handshake timeout, 10s when 0"},
+	{key: "responseHeaderTimeout", env: "KUBECOST_RESPONSE_HEADER_TIMEOUT", description: "Time to wait for response headers, unlimited when 0"},
+	{key: "idleConnTimeout", env: "KUBECOST_IDLE_CONN_TIMEOUT", description: "How long idle connections are kept, 90s when 0"},
+	{key: "maxIdleConns", env: "KUBECOST_MAX_IDLE_CONNS", description: "Idle connection pool size, 100 when 0"},
+	{key: "maxIdleConnsPerHost", env: "KUBECOST_MAX_IDLE_CONNS_PER_HOST", description: "Idle connections kept per host, 10 when 0"},
+	{key: "maxConnsPerHost", env: "KUBECOST_MAX_CONNS_PER_HOST", description: "Maximum connections per host, unlimited when 0"},
+	{key: "disableHttp2", env: "KUBECOST_DISABLE_HTTP2", description: "Use HTTP/1.1 only"},
+	{key: "disableCompression", env: "KUBECOST_DISABLE_COMPRESSION", description: "Do not request gzip-compressed responses"},
+	{
+		key: "proxyUrl", env: "KUBECOST_PROXY_URL", secret: true,
+		description: "http, https, socks5 or socks5h proxy for Kubecost requests; HTTPS_PROXY applies when unset",
+	},
+	{key: "noProxy", env: "KUBECOST_NO_PROXY", description: "Comma-separated hosts that bypass the proxy, overrides NO_PROXY"},
+	{key: "logLevel", env: "KUBECOST_LOG_LEVEL", description: "Minimum level logged", enum: []string{"debug", "info", "warn", "error"}},
+	{key: "logFormat", env: "KUBECOST_LOG_FORMAT", description: "Log record format", enum: []string{logging.FormatText, logging.FormatJSON}},
+	{
+		key: "tracingExporter", env: "KUBECOST_TRACING_EXPORTER",
+		description: "OpenTelemetry span exporter, read at startup",
+		enum:        []string{tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP},
+	},
+	{key: "otlpEndpoint", env: "KUBECOST_OTLP_ENDPOINT", description: "OTLP gRPC collector host:port, OTEL_EXPORTER_OTLP_ENDPOINT applies when empty"},
+	{key: "otlpInsecure", env: "KUBECOST_OTLP_INSECURE", description: "Send OTLP without TLS, e.g. to a local collector"},
+	{key: "metricsAddress", env: "KUBECOST_METRICS_ADDRESS", description: "host:port serving Prometheus metrics on /metrics, disabled when empty; read at startup"},
+	{key: "rpcTimeout", env: "KUBECOST_RPC_TIMEOUT", description: "Deadline for unary RPCs sent without one, 0 disables; read at startup"},
+	{key: "streamRpcTimeout", env: "KUBECOST_STREAM_RPC_TIMEOUT", description: "Deadline for streaming RPCs sent without one, 0 disables; read at startup"},
+	{key: "listenAddress", env: "KUBECOST_LISTEN_ADDRESS", description: "host:port the plugin's gRPC server listens on; read at startup"},
+	{key: "serverCertFile", env: "KUBECOST_SERVER_CERT_FILE", description: "PEM certificate enabling TLS on the plugin's gRPC server"},
+	{key: "serverKeyFile", env: "KUBECOST_SERVER_KEY_FILE", description: "PEM private key for serverCertFile"},
+	{key: "serverClientCaFile", env: "KUBECOST_SERVER_CLIENT_CA_FILE", description: "PEM CAs that must have signed client certificates, enabling mutual TLS"},
+	{
+		key: "serverAuthToken", env: "KUBECOST_SERVER_AUTH_TOKEN", secret: true,
+		description: "Token callers must send as \"authorization: Bearer <token>\" gRPC metadata, needs serverCertFile beyond loopback; read at startup",
+	},
+}
+
+// FieldInfo describes a configuration key for generated documentation.
+type FieldInfo struct {
+	Key         string
+	Env         string
+	Type        string // JSON type: string, boolean, integer, number, array or object
+	Duration    bool   // a Go duration string such as "15s"
+	Description string
+	Default     any // nil when the key has no non-zero default
+	Enum        []string
+	Required    bool
+	Secret      bool // credentials that must not be logged
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ConfigFields describes every configuration key, deriving types and defaults
+// from Config.
+func ConfigFields() []FieldInfo {
+	defaults := reflect.ValueOf(defaultConfig())
+	out := make([]FieldInfo, 0, len(configFields))
+	for _, f := range configFields {
+		v := defaults.Field(configKeys[f.key])
+		info := FieldInfo{
+			Key:         f.key,
+			Env:         f.env,
+			Type:        jsonType(v.Type()),
+			Duration:    v.Type() == durationType,
+			Description: f.description,
+			Enum:        f.enum,
+			Required:    f.required,
+			Secret:      f.secret,
+		}
+		switch {
+		case info.Duration && !v.IsZero():
+			info.Default = v.Interface().(time.Duration).String()
+		case v.Kind() == reflect.Bool:
+			info.Default = v.Bool()
+		case !v.IsZero():
+			info.Default = v.Interface()
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// Secrets returns the credential values set in c, so they can be masked
+// wherever they might be echoed back, such as Kubecost error bodies.
+func (c Config) Secrets() []string {
+	v := reflect.ValueOf(c)
+	var secrets []string
+	for _, f := range configFields {
+		if !f.secret {
+			continue
+		}
+		s := v.Field(configKeys[f.key]).String()
+		if f.key == "proxyUrl" {
+			// Only the password in the proxy URL is secret
+			s = ""
+			if u, err := url.Parse(c.ProxyURL); err == nil {
+				s, _ = u.User.Password()
+			}
+		}
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
+}
+
+// SecretKeys returns the configuration keys that hold credentials.
+func SecretKeys() []string {
+	var keys []string
+	for _, f := range configFields {
+		if f.secret {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() { //nolint:exhaustive // Config only uses these kinds
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		if t == durationType {
+			return "string"
+		}
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "array"
+	case reflect.Map:
+		return "object"
+	}
+	return "string"
+}
@@ -0,0 +1,85 @@
+package kubecost
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/metrics"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/tracing"
+)
+
+// cacheHitAttr records whether a traced lookup was served from a cache.
+const cacheHitAttr = "kubecost.cache_hit"
+
+// oauth2TokenCache names the OAuth2 access token cache in metrics.
+const oauth2TokenCache = "oauth2_token"
+
+// startSpan starts a client span for a Kubecost request and injects its trace
+// context into the request headers, so Kubecost or a tracing proxy in front of
+// it can join the trace.
+func startSpan(req *http.Request) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(req.Context(), "kubecost "+req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLPath(req.URL.Path),
+			semconv.ServerAddress(req.URL.Hostname()),
+		))
+	if filter := req.URL.Query().Get("filter"); filter != "" {
+		span.SetAttributes(attribute.String("kubecost.filter", filter))
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return ctx, span
+}
+
+// observeResponse records the response status on span and in the request
+// metrics, and ends span when the body is closed, recording the number of body
+// bytes read.
+func observeResponse(span trace.Span, req *http.Request, resp *http.Response, latency time.Duration) *http.Response {
+	metrics.ObserveUpstream(req.URL.Path, resp.StatusCode, latency)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= httpClientError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	resp.Body = &observedBody{ReadCloser: resp.Body, span: span, endpoint: req.URL.Path}
+	return resp
+}
+
+// observeFailure records a request that got no response.
+func observeFailure(span trace.Span, req *http.Request, latency time.Duration, err error) {
+	metrics.ObserveUpstream(req.URL.Path, 0, latency)
+	tracing.End(span, err)
+}
+
+// observedBody counts the bytes read from a response body and records them
+// when the body is closed.
+type observedBody struct {
+	io.ReadCloser
+
+	span     trace.Span
+	endpoint string
+	n        int
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += n
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.span.SetAttributes(semconv.HTTPResponseBodySize(b.n))
+	b.span.End()
+	metrics.ObserveUpstreamBytes(b.endpoint, b.n)
+	return err
+}
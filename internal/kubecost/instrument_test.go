@@ -0,0 +1,65 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClientTracesRequests(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"code": 200, "data": [{}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "rpc")
+	_, err = client.GetDetailedAllocation(ctx, AllocationQuery{Window: "1d", Filter: map[string]string{"namespace": "prod"}})
+	parent.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected request and parent spans, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("Expected the Kubecost span to be a child of the caller's span")
+	}
+	if traceparent == "" || span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("Expected trace context sent to Kubecost, got traceparent %q", traceparent)
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["url.path"].AsString() != allocationPath || attrs["http.response.status_code"].AsInt64() != http.StatusOK {
+		t.Errorf("Expected path and status attributes, got %v", attrs)
+	}
+	if attrs["kubecost.filter"].AsString() == "" || attrs["http.response.body.size"].AsInt64() == 0 {
+		t.Errorf("Expected filter and response size attributes, got %v", attrs)
+	}
+}
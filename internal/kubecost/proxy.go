@@ -0,0 +1,65 @@
+package kubecost
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newProxyFunc returns the proxy selector for the Kubecost transport. The
+// standard HTTPS_PROXY, HTTP_PROXY and NO_PROXY variables apply unless cfg sets
+// ProxyURL or NoProxy. Every proxy URL is validated up front so request-time
+// errors cannot echo proxy credentials.
+func newProxyFunc(cfg Config) (func(*http.Request) (*url.URL, error), error) {
+	proxyCfg := httpproxy.FromEnvironment()
+	if cfg.ProxyURL != "" {
+		proxyCfg.HTTPProxy = cfg.ProxyURL
+		proxyCfg.HTTPSProxy = cfg.ProxyURL
+	}
+	if cfg.NoProxy != "" {
+		proxyCfg.NoProxy = cfg.NoProxy
+	}
+	for _, raw := range []string{proxyCfg.HTTPProxy, proxyCfg.HTTPSProxy} {
+		if raw == "" {
+			continue
+		}
+		if _, err := parseProxyURL(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	proxyFor := proxyCfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFor(req.URL)
+	}, nil
+}
+
+// parseProxyURL parses and checks a proxy URL, keeping credentials out of the
+// returned error.
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %s", RedactURL(raw))
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %q in %s (use http, https, socks5 or socks5h)",
+		u.Scheme, RedactURL(raw))
+}
+
+// RedactURL masks the password in a URL such as a proxy address so it can be
+// logged. Unparseable input with user info is masked entirely before the host.
+func RedactURL(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Redacted()
+	}
+	if i := strings.LastIndex(raw, "@"); i >= 0 {
+		return "xxxxx" + raw[i:]
+	}
+	return raw
+}
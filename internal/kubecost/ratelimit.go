@@ -0,0 +1,88 @@
+package kubecost
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// limiter throttles outgoing Kubecost requests with a token bucket and caps the
+// number of requests in flight. A nil limiter imposes no limits.
+type limiter struct {
+	bucket *rate.Limiter
+	slots  chan struct{}
+}
+
+// newLimiter builds a limiter from the rate limiting settings in cfg. It returns
+// nil when neither a rate limit nor a concurrency cap is configured.
+func newLimiter(cfg Config) *limiter {
+	if cfg.RateLimit <= 0 && cfg.MaxConcurrency <= 0 {
+		return nil
+	}
+
+	l := &limiter{}
+	if cfg.RateLimit > 0 {
+		burst := cfg.RateBurst
+		if burst <= 0 {
+			burst = 1
+		}
+		l.bucket = rate.NewLimiter(rate.Limit(cfg.RateLimit), burst)
+	}
+	if cfg.MaxConcurrency > 0 {
+		l.slots = make(chan struct{}, cfg.MaxConcurrency)
+	}
+	return l
+}
+
+// acquire blocks until a request may be sent, honoring the deadline and
+// cancellation of ctx while queued. The returned release func must be called
+// once the request, including reading its body, has finished.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for kubecost request slot: %w", ctx.Err())
+		}
+	}
+
+	if l.bucket != nil {
+		if err := l.bucket.Wait(ctx); err != nil {
+			l.releaseSlot()
+			if ctx.Err() == nil {
+				// Wait gives up at once when the token would arrive after the deadline
+				err = fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+			}
+			return nil, fmt.Errorf("waiting for kubecost rate limit: %w", err)
+		}
+	}
+
+	var once sync.Once
+	return func() { once.Do(l.releaseSlot) }, nil
+}
+
+func (l *limiter) releaseSlot() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// releaseOnClose releases a limiter slot when the wrapped body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
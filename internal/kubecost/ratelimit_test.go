@@ -0,0 +1,114 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewLimiterDisabled(t *testing.T) {
+	if l := newLimiter(Config{}); l != nil {
+		t.Error("Expected nil limiter when no limits are configured")
+	}
+
+	// A nil limiter must never block
+	var l *limiter
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire on nil limiter failed: %v", err)
+	}
+	release()
+}
+
+func TestLimiterConcurrencyCap(t *testing.T) {
+	l := newLimiter(Config{MaxConcurrency: 1})
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first acquire failed: %v", err)
+	}
+
+	// Second acquire should wait and give up when its deadline passes
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while queued, got %v", err)
+	}
+
+	// Releasing twice must not free more than one slot
+	release()
+	release()
+
+	release2, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire after release failed: %v", err)
+	}
+	defer release2()
+	if len(l.slots) != 1 {
+		t.Errorf("Expected 1 slot in use, got %d", len(l.slots))
+	}
+}
+
+func TestLimiterRateLimit(t *testing.T) {
+	l := newLimiter(Config{RateLimit: 1, RateBurst: 1})
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first acquire failed: %v", err)
+	}
+	release()
+
+	// The bucket is empty, so the next request cannot be admitted within 20ms
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected rate limit wait to fail with deadline exceeded, got %v", err)
+	}
+}
+
+func TestClientMaxConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:        server.URL,
+		MaxConcurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, allocErr := client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); allocErr != nil {
+				t.Errorf("Allocation failed: %v", allocErr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", peak)
+	}
+}
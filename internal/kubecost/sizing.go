@@ -0,0 +1,117 @@
+package kubecost
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RequestSizingQuery selects container request-sizing recommendations.
+type RequestSizingQuery struct {
+	Window string            // usage window recommendations are based on, e.g. "3d"
+	Filter map[string]string // namespace, controllerName, controllerKind, cluster, container
+	// Target utilizations, 0-1, that requests are sized for; 0 leaves
+	// Kubecost's default.
+	TargetCPUUtilization float64
+	TargetRAMUtilization float64
+}
+
+// RequestSizingResponse is the request-sizing savings API response.
+type RequestSizingResponse struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message,omitempty"`
+	Data    RequestSizingData `json:"data"`
+}
+
+// RequestSizingData holds one recommendation per container.
+type RequestSizingData struct {
+	Recommendations     []SizingRecommendation `json:"Recommendations"`
+	TotalMonthlySavings float64                `json:"TotalMonthlySavings"`
+}
+
+// SizingRecommendation is the recommended CPU and memory request of one
+// container, with what it would save a month over its latest known request.
+type SizingRecommendation struct {
+	ClusterID          string          `json:"clusterID"`
+	Namespace          string          `json:"namespace"`
+	ControllerKind     string          `json:"controllerKind"`
+	ControllerName     string          `json:"controllerName"`
+	ContainerName      string          `json:"containerName"`
+	RecommendedRequest ResourceRequest `json:"recommendedRequest"`
+	LatestKnownRequest ResourceRequest `json:"latestKnownRequest"`
+	MonthlySavings     SizingSavings   `json:"monthlySavings"`
+	CurrentEfficiency  SizingUsage     `json:"currentEfficiency"`
+}
+
+// ResourceRequest is a container's CPU and memory request as Kubernetes
+// quantities, e.g. "250m" and "512Mi".
+type ResourceRequest struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// SizingSavings is a recommendation's estimated monthly savings.
+type SizingSavings struct {
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+}
+
+// Total returns the CPU and memory savings combined.
+func (s SizingSavings) Total() float64 {
+	return s.CPU + s.Memory
+}
+
+// SizingUsage is a container's current utilization of its requests.
+type SizingUsage struct {
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+	Total  float64 `json:"total"`
+}
+
+// BuildRequestSizingURL constructs the URL for the Kubecost request-sizing
+// savings API.
+func (c *Client) BuildRequestSizingURL(q RequestSizingQuery) (string, error) {
+	u, err := url.Parse(c.cfg.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	u.Path = sizingPath
+
+	params := url.Values{}
+	params.Set("window", q.Window)
+	if filters := filterClauses(q.Filter); len(filters) > 0 {
+		params.Set("filter", strings.Join(filters, "+"))
+	}
+	if q.TargetCPUUtilization > 0 {
+		params.Set("targetCPUUtilization", strconv.FormatFloat(q.TargetCPUUtilization, 'f', -1, 64))
+	}
+	if q.TargetRAMUtilization > 0 {
+		params.Set("targetRAMUtilization", strconv.FormatFloat(q.TargetRAMUtilization, 'f', -1, 64))
+	}
+
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// RequestSizing queries Kubecost for container request-sizing recommendations
+// and the savings they would bring.
+func (c *Client) RequestSizing(ctx context.Context, q RequestSizingQuery) (*RequestSizingResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	url, err := c.BuildRequestSizingURL(q)
+	if err != nil {
+		return nil, err
+	}
+	var out RequestSizingResponse
+	if err = c.getJSON(ctx, sizingPath, url, &out); err != nil {
+		return nil, err
+	}
+	out.Message = c.redactor.String(out.Message)
+	if out.Code != httpSuccessStatus {
+		return nil, payloadError(sizingPath, out.Code, out.Message)
+	}
+	return &out, nil
+}
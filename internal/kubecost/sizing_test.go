@@ -0,0 +1,95 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const sizingFixture = `{
+	"code": 200,
+	"data": {
+		"Recommendations": [{
+			"clusterID": "cluster-one",
+			"namespace": "shop",
+			"controllerKind": "deployment",
+			"controllerName": "api",
+			"containerName": "server",
+			"recommendedRequest": {"cpu": "120m", "memory": "300Mi"},
+			"latestKnownRequest": {"cpu": "1", "memory": "1Gi"},
+			"monthlySavings": {"cpu": 18.5, "memory": 3.25},
+			"currentEfficiency": {"cpu": 0.08, "memory": 0.2, "total": 0.11}
+		}],
+		"TotalMonthlySavings": 21.75
+	}
+}`
+
+func TestBuildRequestSizingURL(t *testing.T) {
+	client := &Client{cfg: Config{BaseURL: "http://kubecost:9090"}}
+	raw, err := client.BuildRequestSizingURL(RequestSizingQuery{
+		Window:               "3d",
+		Filter:               map[string]string{"namespace": "shop", "controllerName": "api"},
+		TargetCPUUtilization: 0.65,
+	})
+	if err != nil {
+		t.Fatalf("BuildRequestSizingURL failed: %v", err)
+	}
+	u, _ := url.Parse(raw)
+	if u.Path != sizingPath {
+		t.Errorf("Expected path %s, got %s", sizingPath, u.Path)
+	}
+	q := u.Query()
+	if got := q.Get("filter"); got != `controllerName:"api"+namespace:"shop"` {
+		t.Errorf("Unexpected filter %s", got)
+	}
+	if q.Get("window") != "3d" || q.Get("targetCPUUtilization") != "0.65" || q.Has("targetRAMUtilization") {
+		t.Errorf("Unexpected query %s", u.RawQuery)
+	}
+}
+
+func TestRequestSizing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != sizingPath {
+			t.Errorf("Expected path %s, got %s", sizingPath, r.URL.Path)
+		}
+		w.Write([]byte(sizingFixture))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	resp, err := client.RequestSizing(context.Background(), RequestSizingQuery{Window: "3d"})
+	if err != nil {
+		t.Fatalf("RequestSizing failed: %v", err)
+	}
+	if len(resp.Data.Recommendations) != 1 || resp.Data.TotalMonthlySavings != 21.75 {
+		t.Fatalf("Unexpected data %+v", resp.Data)
+	}
+	rec := resp.Data.Recommendations[0]
+	if rec.ControllerName != "api" || rec.LatestKnownRequest.CPU != "1" || rec.RecommendedRequest.Memory != "300Mi" {
+		t.Errorf("Unexpected recommendation %+v", rec)
+	}
+	if rec.MonthlySavings.Total() != 21.75 || rec.CurrentEfficiency.CPU != 0.08 {
+		t.Errorf("Unexpected savings or efficiency %+v", rec)
+	}
+}
+
+func TestRequestSizingPayloadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"code": 400, "message": "invalid window"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = client.RequestSizing(context.Background(), RequestSizingQuery{Window: "bogus"}); !errors.Is(err, ErrBadQuery) {
+		t.Errorf("Expected a bad query error, got %v", err)
+	}
+}
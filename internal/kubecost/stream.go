@@ -0,0 +1,277 @@
+package kubecost
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
+)
+
+// maxErrorBodyBytes caps how much of an error response body is captured into
+// error messages.
+const maxErrorBodyBytes = 4 << 10
+
+// AllocationVisitor is called for each allocation entry as it is decoded from
+// the response. window is the index of the entry's window in the data array.
+// Returning an error stops decoding and is returned from the stream call.
+type AllocationVisitor func(window int, name string, entry AllocationEntry) error
+
+// allocationEnvelope holds the top-level fields of an allocation response.
+type allocationEnvelope struct {
+	Code    int
+	Status  string
+	Message string
+	Windows int
+}
+
+// StreamAllocation queries the Kubecost allocation API and calls visit for each
+// entry as it is decoded, without holding the full response in memory. Entries
+// may have been visited before a Kubecost error payload is detected. The query
+// is bounded like a stream, see streamTimeout.
+func (c *Client) StreamAllocation(ctx context.Context, q AllocationQuery, visit AllocationVisitor) error {
+	ctx, cancel := c.streamTimeout(ctx)
+	defer cancel()
+	_, err := c.streamAllocation(ctx, q, visit)
+	return err
+}
+
+// streamTimeout bounds a query whose visitor may block for long, such as on
+// gRPC flow control while sending to the host, so Config.Timeout would cut it
+// off while Kubecost is idle: when ctx has a deadline, such as a streaming
+// RPC's, it alone applies; otherwise the query gets Config.Timeout.
+func (c *Client) streamTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return c.withTimeout(ctx)
+}
+
+// streamAllocation runs an allocation query within the deadline of ctx, which
+// callers bound with withTimeout or streamTimeout.
+func (c *Client) streamAllocation(
+	ctx context.Context,
+	q AllocationQuery,
+	visit AllocationVisitor,
+) (allocationEnvelope, error) {
+	url, err := c.BuildAllocationURL(q)
+	if err != nil {
+		return allocationEnvelope{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return allocationEnvelope{}, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if !c.cfg.DisableCompression {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return allocationEnvelope{}, transportError(allocationPath, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := decodedBody(resp)
+	if err != nil {
+		return allocationEnvelope{}, decodeError(allocationPath, err)
+	}
+	defer body.Close()
+
+	if resp.StatusCode >= httpClientErrorStatus {
+		return allocationEnvelope{}, statusError(allocationPath, resp, c.errorBody(body))
+	}
+
+	env, err := decodeAllocationStream(body, visit)
+	if err != nil {
+		var ve *visitError
+		switch {
+		case errors.As(err, &ve):
+			return env, ve.err
+		case ctx.Err() != nil:
+			return env, transportError(allocationPath, ctx.Err())
+		}
+		return env, decodeError(allocationPath, err)
+	}
+
+	// The message reaches callers of GetDetailedAllocation and error messages
+	env.Message = c.redactor.String(env.Message)
+	if env.Code != httpSuccessStatus {
+		return env, payloadError(allocationPath, env.Code, env.Message)
+	}
+
+	return env, nil
+}
+
+// visitError marks an error returned by an AllocationVisitor so it is passed
+// through unchanged rather than reported as a decode failure.
+type visitError struct {
+	err error
+}
+
+func (e *visitError) Error() string { return e.err.Error() }
+
+func (e *visitError) Unwrap() error { return e.err }
+
+// decodedBody returns a reader over the response body, transparently
+// decompressing gzip-encoded responses.
+func decodedBody(resp *http.Response) (io.ReadCloser, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.NopCloser(resp.Body), nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading gzip response: %w", err)
+	}
+	return zr, nil
+}
+
+// readErrorBody reads at most maxErrorBodyBytes from r for inclusion in an
+// error message.
+func readErrorBody(r io.Reader) string {
+	b, _ := io.ReadAll(io.LimitReader(r, maxErrorBodyBytes+1))
+	if len(b) > maxErrorBodyBytes {
+		return logging.Redact(string(b[:maxErrorBodyBytes])) + "...(truncated)"
+	}
+	return logging.Redact(string(b))
+}
+
+// decodeAllocationStream walks an allocation response token by token, decoding
+// one AllocationEntry at a time from the data windows.
+func decodeAllocationStream(r io.Reader, visit AllocationVisitor) (allocationEnvelope, error) {
+	var env allocationEnvelope
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return env, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return env, err
+		}
+		switch key {
+		case "code":
+			err = dec.Decode(&env.Code)
+		case "status":
+			err = dec.Decode(&env.Status)
+		case "message":
+			err = dec.Decode(&env.Message)
+		case "data":
+			env.Windows, err = decodeAllocationData(dec, visit)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return env, err
+		}
+	}
+	return env, expectDelim(dec, '}')
+}
+
+// decodeAllocationData decodes the data array of window objects and returns the
+// number of windows seen.
+func decodeAllocationData(dec *json.Decoder, visit AllocationVisitor) (int, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if tok == nil {
+		return 0, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return 0, fmt.Errorf("expected data array, got %v", tok)
+	}
+
+	window := 0
+	for ; dec.More(); window++ {
+		tok, err = dec.Token()
+		if err != nil {
+			return window, err
+		}
+		if tok == nil {
+			continue
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+			return window, fmt.Errorf("expected allocation window object, got %v", tok)
+		}
+		if err = decodeAllocationWindow(dec, window, visit); err != nil {
+			return window, err
+		}
+	}
+	return window, expectDelim(dec, ']')
+}
+
+func decodeAllocationWindow(dec *json.Decoder, window int, visit AllocationVisitor) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected allocation name, got %v", tok)
+		}
+		var entry AllocationEntry
+		if err = dec.Decode(&entry); err != nil {
+			return fmt.Errorf("allocation %q: %w", name, err)
+		}
+		if err = visit(window, name, entry); err != nil {
+			return &visitError{err: err}
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// WindowTotals aggregates streamed allocation entries into one AllocationPoint
+// per window, so only the running totals are kept in memory.
+type WindowTotals struct {
+	points []AllocationPoint
+}
+
+// Visit adds entry to the totals of its window. It satisfies AllocationVisitor.
+func (w *WindowTotals) Visit(window int, _ string, entry AllocationEntry) error {
+	for len(w.points) <= window {
+		w.points = append(w.points, AllocationPoint{})
+	}
+	p := &w.points[window]
+	e := entry.ToPoint()
+	if p.Start == "" {
+		p.Start, p.End = e.Start, e.End
+	}
+	p.Cost += e.Cost
+	p.CPUCost += e.CPUCost
+	p.RAMCost += e.RAMCost
+	p.GPUCost += e.GPUCost
+	p.PVCCost += e.PVCCost
+	p.NetworkCost += e.NetworkCost
+	return nil
+}
+
+// Points returns the per-window totals in window order.
+func (w *WindowTotals) Points() []AllocationPoint {
+	return w.points
+}
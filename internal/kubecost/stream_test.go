@@ -0,0 +1,171 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const streamFixture = `{
+	"code": 200,
+	"status": "success",
+	"extra": {"ignored": [1, 2, 3]},
+	"data": [
+		{
+			"pod-a": {"name": "pod-a", "start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z", "totalCost": 1.5, "cpuCost": 1},
+			"pod-b": {"name": "pod-b", "start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z", "totalCost": 2.5, "cpuCost": 2}
+		},
+		{},
+		{
+			"pod-a": {"name": "pod-a", "window": {"start": "2024-01-03T00:00:00Z", "end": "2024-01-04T00:00:00Z"}, "totalCost": 3}
+		}
+	]
+}`
+
+func TestDecodeAllocationStream(t *testing.T) {
+	var names []string
+	var windows []int
+	env, err := decodeAllocationStream(strings.NewReader(streamFixture), func(w int, name string, _ AllocationEntry) error {
+		windows = append(windows, w)
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("decodeAllocationStream failed: %v", err)
+	}
+
+	if env.Code != 200 || env.Status != "success" {
+		t.Errorf("Unexpected envelope: %+v", env)
+	}
+	if env.Windows != 3 {
+		t.Errorf("Expected 3 windows, got %d", env.Windows)
+	}
+	if strings.Join(names, ",") != "pod-a,pod-b,pod-a" {
+		t.Errorf("Expected entries in document order, got %v", names)
+	}
+	if windows[2] != 2 {
+		t.Errorf("Expected last entry in window 2, got %d", windows[2])
+	}
+}
+
+func TestDecodeAllocationStreamVisitorError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	_, err := decodeAllocationStream(strings.NewReader(streamFixture), func(int, string, AllocationEntry) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected visitor error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected decoding to stop after 1 entry, got %d", calls)
+	}
+}
+
+func TestDecodeAllocationStreamMalformed(t *testing.T) {
+	inputs := []string{
+		``,
+		`[]`,
+		`{"data": {"not": "an array"}}`,
+		`{"data": [{"pod-a": {"totalCost": 1}`,
+	}
+	for _, in := range inputs {
+		if _, err := decodeAllocationStream(strings.NewReader(in), func(int, string, AllocationEntry) error {
+			return nil
+		}); err == nil {
+			t.Errorf("Expected error for %q", in)
+		}
+	}
+}
+
+func TestWindowTotals(t *testing.T) {
+	var totals WindowTotals
+	if _, err := decodeAllocationStream(strings.NewReader(streamFixture), totals.Visit); err != nil {
+		t.Fatalf("decodeAllocationStream failed: %v", err)
+	}
+
+	points := totals.Points()
+	if len(points) != 3 {
+		t.Fatalf("Expected 3 window totals, got %d", len(points))
+	}
+	if points[0].Cost != 4 || points[0].CPUCost != 3 {
+		t.Errorf("Expected first window cost 4 (cpu 3), got %f (cpu %f)", points[0].Cost, points[0].CPUCost)
+	}
+	if points[1].Cost != 0 {
+		t.Errorf("Expected empty window to total 0, got %f", points[1].Cost)
+	}
+	if points[2].Start != "2024-01-03T00:00:00Z" {
+		t.Errorf("Expected window start from entry window, got %s", points[2].Start)
+	}
+}
+
+func TestStreamAllocationGzip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Error("Expected Accept-Encoding: gzip")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(streamFixture))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	resp, err := client.GetDetailedAllocation(context.Background(), AllocationQuery{Window: "3d"})
+	if err != nil {
+		t.Fatalf("GetDetailedAllocation failed: %v", err)
+	}
+	if len(resp.Data) != 3 {
+		t.Errorf("Expected 3 windows including the empty one, got %d", len(resp.Data))
+	}
+	if resp.Data[0]["pod-b"].TotalCost != 2.5 {
+		t.Errorf("Expected pod-b cost 2.5, got %f", resp.Data[0]["pod-b"].TotalCost)
+	}
+}
+
+func TestStreamAllocationKubecostErrorCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code": 400, "message": "bad window"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	err = client.StreamAllocation(context.Background(), AllocationQuery{Window: "x"}, func(int, string, AllocationEntry) error {
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "bad window") {
+		t.Errorf("Expected Kubecost error message, got %v", err)
+	}
+}
+
+func TestReadErrorBodyTruncates(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), maxErrorBodyBytes*2)
+	got := readErrorBody(bytes.NewReader(body))
+	if !strings.HasSuffix(got, "...(truncated)") {
+		t.Error("Expected truncation marker")
+	}
+	if len(got) > maxErrorBodyBytes+len("...(truncated)") {
+		t.Errorf("Expected capped body, got %d bytes", len(got))
+	}
+
+	if got = readErrorBody(strings.NewReader("short")); got != "short" {
+		t.Errorf("Expected short body unchanged, got %q", got)
+	}
+}
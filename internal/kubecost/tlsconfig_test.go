@@ -0,0 +1,251 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testCA is a throwaway certificate authority for TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating CA key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns PEM-encoded certificate and key signed by the CA.
+func (ca *testCA) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "kubecost.test"},
+		DNSNames:     []string{"kubecost.test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshaling key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+// newMTLSServer starts a server that requires client certificates from ca and
+// records the serial number of the last client certificate it saw.
+func newMTLSServer(t *testing.T, ca *testCA) (*httptest.Server, func() int64) {
+	t.Helper()
+	var mu sync.Mutex
+	var lastSerial int64
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastSerial = r.TLS.PeerCertificates[0].SerialNumber.Int64()
+		mu.Unlock()
+		w.Write([]byte(`{"items": []}`))
+	}))
+	certPEM, keyPEM := ca.issue(t, 100, x509.ExtKeyUsageServerAuth)
+	serverCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("loading server key pair: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+
+	return server, func() int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return lastSerial
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	if v, err := parseTLSVersion(""); err != nil || v != tls.VersionTLS12 {
+		t.Errorf("Expected default TLS 1.2, got %x (%v)", v, err)
+	}
+	if v, err := parseTLSVersion("1.3"); err != nil || v != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3, got %x (%v)", v, err)
+	}
+	if _, err := parseTLSVersion("1.0"); err == nil {
+		t.Error("Expected error for TLS 1.0")
+	}
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	bogus := filepath.Join(dir, "bogus.pem")
+	writeFile(t, bogus, []byte("not a certificate"))
+
+	testCases := map[string]Config{
+		"missing CA file":  {CAFile: filepath.Join(dir, "missing.pem")},
+		"invalid CA file":  {CAFile: bogus},
+		"cert without key": {ClientCertFile: bogus},
+		"invalid key pair": {ClientCertFile: bogus, ClientKeyFile: bogus},
+		"bad min version":  {TLSMinVersion: "1.1"},
+	}
+	for name, cfg := range testCases {
+		if _, err := NewClient(context.Background(), cfg); err == nil {
+			t.Errorf("%s: expected NewClient to fail", name)
+		}
+	}
+}
+
+func TestMutualTLSWithCertRotation(t *testing.T) {
+	ca := newTestCA(t)
+	server, lastSerial := newMTLSServer(t, ca)
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writeFile(t, caFile, ca.pem)
+	certPEM, keyPEM := ca.issue(t, 1, x509.ExtKeyUsageClientAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:        server.URL,
+		CAFile:         caFile,
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+		TLSMinVersion:  "1.2",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("Allocation over mTLS failed: %v", err)
+	}
+	if got := lastSerial(); got != 1 {
+		t.Errorf("Expected client certificate serial 1, got %d", got)
+	}
+
+	// Rotate the client certificate on disk with a newer modification time
+	certPEM, keyPEM = ca.issue(t, 2, x509.ExtKeyUsageClientAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	future := time.Now().Add(time.Minute)
+	for _, p := range []string{certFile, keyFile} {
+		if err = os.Chtimes(p, future, future); err != nil {
+			t.Fatalf("Chtimes failed: %v", err)
+		}
+	}
+	rt, ok := client.http.Transport.(*reloadingTransport)
+	if !ok {
+		t.Fatalf("Expected reloading transport, got %T", client.http.Transport)
+	}
+	rt.current.Invalidate()
+
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Fatalf("Allocation after rotation failed: %v", err)
+	}
+	if got := lastSerial(); got != 2 {
+		t.Errorf("Expected rotated client certificate serial 2, got %d", got)
+	}
+}
+
+func TestCustomCAWithServerName(t *testing.T) {
+	ca := newTestCA(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"items": []}`))
+	}))
+	certPEM, keyPEM := ca.issue(t, 100, x509.ExtKeyUsageServerAuth)
+	serverCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("loading server key pair: %v", err)
+	}
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert}, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, ca.pem)
+
+	client, err := NewClient(context.Background(), Config{
+		BaseURL:    server.URL,
+		CAFile:     caFile,
+		ServerName: "kubecost.test",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Errorf("Expected request to verify against custom CA, got %v", err)
+	}
+
+	wrongName, err := NewClient(context.Background(), Config{
+		BaseURL:    server.URL,
+		CAFile:     caFile,
+		ServerName: "other.test",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = wrongName.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err == nil {
+		t.Error("Expected verification failure for mismatched server name")
+	}
+}
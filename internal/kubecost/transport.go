@@ -0,0 +1,85 @@
+package kubecost
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Transport defaults applied when the corresponding Config field is zero.
+const (
+	defaultDialTimeout         = 5 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+)
+
+// newHTTPClient builds the HTTP client used for every Kubecost call, applying
+// the TLS, connection pooling, HTTP/2 and compression settings of cfg. The
+// request timeout is applied per operation through the request context; see
+// Client.withTimeout.
+func newHTTPClient(cfg Config) (*http.Client, error) {
+	var transport http.RoundTripper
+	var err error
+	if len(tlsFiles(cfg)) > 0 {
+		transport, err = newReloadingTransport(cfg)
+	} else {
+		transport, err = newTransport(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+func newTransport(cfg Config) (*http.Transport, error) {
+	tlsCfg, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := newProxyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   durationOr(cfg.DialTimeout, defaultDialTimeout),
+		KeepAlive: defaultKeepAlive,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsCfg,
+		TLSHandshakeTimeout:   durationOr(cfg.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       durationOr(cfg.IdleConnTimeout, defaultIdleConnTimeout),
+		MaxIdleConns:          intOr(cfg.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost:   intOr(cfg.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		DisableCompression:    cfg.DisableCompression,
+	}
+	if cfg.DisableHTTP2 {
+		// A non-nil, empty map turns off the transport's automatic HTTP/2 upgrade
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport, nil
+}
+
+func durationOr(v, def time.Duration) time.Duration {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+func intOr(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
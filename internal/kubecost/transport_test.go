@@ -0,0 +1,133 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewTransportDefaults(t *testing.T) {
+	transport, err := newTransport(Config{})
+	if err != nil {
+		t.Fatalf("newTransport failed: %v", err)
+	}
+
+	if transport.MaxIdleConns != defaultMaxIdleConns {
+		t.Errorf("Expected MaxIdleConns %d, got %d", defaultMaxIdleConns, transport.MaxIdleConns)
+	}
+	if transport.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost {
+		t.Errorf("Expected MaxIdleConnsPerHost %d, got %d", defaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != defaultIdleConnTimeout {
+		t.Errorf("Expected IdleConnTimeout %v, got %v", defaultIdleConnTimeout, transport.IdleConnTimeout)
+	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Error("Expected HTTP/2 to be attempted by default")
+	}
+	if transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("Expected TLS verification by default")
+	}
+}
+
+func TestNewTransportOverrides(t *testing.T) {
+	transport, err := newTransport(Config{
+		TLSSkipVerify:         true,
+		ResponseHeaderTimeout: 3 * time.Second,
+		MaxIdleConns:          7,
+		MaxConnsPerHost:       2,
+		DisableHTTP2:          true,
+		DisableCompression:    true,
+	})
+	if err != nil {
+		t.Fatalf("newTransport failed: %v", err)
+	}
+
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("Expected TLSSkipVerify to be applied")
+	}
+	if transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("Expected ResponseHeaderTimeout 3s, got %v", transport.ResponseHeaderTimeout)
+	}
+	if transport.MaxIdleConns != 7 || transport.MaxConnsPerHost != 2 {
+		t.Errorf("Expected pool sizes 7/2, got %d/%d", transport.MaxIdleConns, transport.MaxConnsPerHost)
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("Expected HTTP/2 to be disabled")
+	}
+	if !transport.DisableCompression {
+		t.Error("Expected compression to be disabled")
+	}
+}
+
+func TestNewClientAppliesTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL, Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if _, err = client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestCallerDeadlineShorterThanTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), Config{BaseURL: server.URL, Timeout: time.Hour})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err = client.Allocation(ctx, AllocationQuery{Window: "1d"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected the caller's deadline to cut the request short, took %v", elapsed)
+	}
+}
+
+func TestNewClientTLSSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	strict, err := NewClient(context.Background(), Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = strict.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err == nil {
+		t.Error("Expected certificate verification failure")
+	}
+
+	insecure, err := NewClient(context.Background(), Config{BaseURL: server.URL, TLSSkipVerify: true})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err = insecure.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err != nil {
+		t.Errorf("Expected request to succeed with TLSSkipVerify, got %v", err)
+	}
+}
+
+func TestUninitializedClient(t *testing.T) {
+	client := &Client{cfg: Config{BaseURL: "http://localhost:9090"}}
+	if _, err := client.Allocation(context.Background(), AllocationQuery{Window: "1d"}); err == nil {
+		t.Error("Expected error for client not built with NewClient")
+	}
+}
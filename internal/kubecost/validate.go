@@ -0,0 +1,326 @@
+package kubecost
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/tracing"
+)
+
+// Problem is one configuration error, located in the file or environment
+// variable that supplied the offending value.
+type Problem struct {
+	Source  string // config file path or environment variable, empty for defaults
+	Line    int    // 1-based line in Source, 0 when not from a file
+	Field   string // YAML key, e.g. "baseUrl"
+	Message string
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Source != "" {
+		b.WriteString(p.Source)
+		if p.Line > 0 {
+			fmt.Fprintf(&b, ":%d", p.Line)
+		}
+		b.WriteString(": ")
+	}
+	if p.Field != "" {
+		b.WriteString(p.Field)
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("invalid configuration (%d problems):\n  %s", len(e.Problems), strings.Join(lines, "\n  "))
+}
+
+// location is where a configuration key was set.
+type location struct {
+	source string
+	line   int
+}
+
+// applyEnv overlays the keys set by KUBECOST_* environment variables onto cfg.
+// Values that do not parse are reported instead of being replaced with the
+// defaults the getenv helpers fall back to.
+func applyEnv(cfg *Config) []Problem {
+	env := envConfig()
+	dst, src := reflect.ValueOf(cfg).Elem(), reflect.ValueOf(env)
+	var problems []Problem
+	for _, f := range configFields {
+		v := os.Getenv(f.env)
+		if v == "" {
+			continue
+		}
+		i := configKeys[f.key]
+		if err := checkEnvValue(f.env, dst.Field(i).Type(), v); err != nil {
+			problems = append(problems, Problem{Source: f.env, Field: f.key, Message: fmt.Sprintf("invalid value %q: %v", v, err)})
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+		cfg.locs[f.key] = location{source: f.env}
+	}
+	return problems
+}
+
+// checkEnvValue reports whether v parses as the type of the Config field it
+// sets, the way envConfig's getenv helpers read it.
+func checkEnvValue(env string, t reflect.Type, v string) error {
+	var err error
+	switch {
+	case t == durationType:
+		_, err = time.ParseDuration(v)
+	case t.Kind() == reflect.Bool:
+		if v != "true" && v != "false" {
+			err = errors.New("must be true or false")
+		}
+	case t.Kind() == reflect.Int:
+		_, err = strconv.Atoi(v)
+	case t.Kind() == reflect.Float64:
+		_, err = strconv.ParseFloat(v, 64)
+	case t.Kind() == reflect.Map:
+		for _, kv := range getenvList(env) {
+			if !strings.Contains(kv, "=") {
+				return fmt.Errorf("entry %q is not key=value", kv)
+			}
+		}
+	}
+	return err
+}
+
+// Validate checks the settings in c, reporting every problem at once as a
+// *ValidationError.
+func (c Config) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// kubecostWindowKeywords are relative windows Kubecost accepts besides durations.
+var kubecostWindowKeywords = map[string]bool{
+	"today": true, "yesterday": true, "week": true, "month": true, "lastweek": true, "lastmonth": true,
+}
+
+func (c Config) problems() []Problem {
+	v := &validator{locs: c.locs}
+
+	if c.BaseURL == "" {
+		v.add("baseUrl", "is required (set baseUrl or KUBECOST_BASE_URL)")
+	} else {
+		v.httpURL("baseUrl", c.BaseURL)
+	}
+	if c.OAuth2TokenURL != "" {
+		v.httpURL("oauth2TokenUrl", c.OAuth2TokenURL)
+	}
+	if c.ProxyURL != "" {
+		if _, err := parseProxyURL(c.ProxyURL); err != nil {
+			v.add("proxyUrl", err.Error())
+		}
+	}
+
+	if c.DefaultWindow != "" && !kubecostWindowKeywords[c.DefaultWindow] {
+		if _, _, err := resolveWindow(c.DefaultWindow); err != nil {
+			v.add("defaultWindow", err.Error())
+		}
+	}
+	v.window("predictionWindow", c.PredictionWindow)
+	v.window("chunkWindow", c.ChunkWindow)
+	v.window("sizingWindow", c.SizingWindow)
+	if c.SizingTargetUtilization < 0 || c.SizingTargetUtilization > 1 {
+		v.add("sizingTargetUtilization", fmt.Sprintf("must be between 0 and 1, got %v", c.SizingTargetUtilization))
+	}
+	if c.ChunkFailurePolicy != "" && c.ChunkFailurePolicy != ChunkPolicyFail && c.ChunkFailurePolicy != ChunkPolicyPartial {
+		v.add("chunkFailurePolicy", fmt.Sprintf("must be %q or %q, got %q", ChunkPolicyFail, ChunkPolicyPartial, c.ChunkFailurePolicy))
+	}
+
+	nonNegative(v, "timeout", c.Timeout)
+	nonNegative(v, "dialTimeout", c.DialTimeout)
+	nonNegative(v, "tlsHandshakeTimeout", c.TLSHandshakeTimeout)
+	nonNegative(v, "responseHeaderTimeout", c.ResponseHeaderTimeout)
+	nonNegative(v, "idleConnTimeout", c.IdleConnTimeout)
+	nonNegative(v, "rateLimit", c.RateLimit)
+	nonNegative(v, "rateBurst", c.RateBurst)
+	nonNegative(v, "maxConcurrency", c.MaxConcurrency)
+	nonNegative(v, "chunkConcurrency", c.ChunkConcurrency)
+	nonNegative(v, "maxIdleConns", c.MaxIdleConns)
+	nonNegative(v, "maxIdleConnsPerHost", c.MaxIdleConnsPerHost)
+	nonNegative(v, "maxConnsPerHost", c.MaxConnsPerHost)
+	nonNegative(v, "rpcTimeout", c.RPCTimeout)
+	nonNegative(v, "streamRpcTimeout", c.StreamRPCTimeout)
+
+	if _, err := parseTLSVersion(c.TLSMinVersion); err != nil {
+		v.add("tlsMinVersion", err.Error())
+	}
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		field := "clientKeyFile"
+		if c.ClientCertFile == "" {
+			field = "clientCertFile"
+		}
+		v.add(field, "clientCertFile and clientKeyFile must be set together")
+	}
+
+	v.auth(c)
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		v.add("logLevel", err.Error())
+	}
+	if c.LogFormat != "" && c.LogFormat != logging.FormatText && c.LogFormat != logging.FormatJSON {
+		v.add("logFormat", fmt.Sprintf("unknown log format %q (use text or json)", c.LogFormat))
+	}
+	if c.MetricsAddress != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddress); err != nil {
+			v.add("metricsAddress", fmt.Sprintf("must be host:port: %v", err))
+		}
+	}
+	c.serverProblems(v)
+	switch c.TracingExporter {
+	case "", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		v.add("tracingExporter", fmt.Sprintf("unknown tracing exporter %q (use none, stdout or otlp)", c.TracingExporter))
+	}
+
+	// Prediction needs a cluster ID unless every request supplies one
+	if c.ClusterID == "" {
+		for _, key := range []string{"predictionWindow", "defaultNamespace"} {
+			if _, set := c.locs[key]; set {
+				v.addAt("clusterId", c.locs[key], fmt.Sprintf("is required when %s is configured for prediction", key))
+				break
+			}
+		}
+	}
+
+	return v.problems
+}
+
+// validator accumulates problems, locating each one where its key was set.
+type validator struct {
+	locs     map[string]location
+	problems []Problem
+}
+
+func (v *validator) add(field, msg string) {
+	v.addAt(field, v.locs[field], msg)
+}
+
+func (v *validator) addAt(field string, loc location, msg string) {
+	v.problems = append(v.problems, Problem{Source: loc.source, Line: loc.line, Field: field, Message: msg})
+}
+
+func (v *validator) httpURL(field, raw string) {
+	u, err := url.Parse(raw)
+	switch {
+	case err != nil:
+		v.add(field, fmt.Sprintf("malformed URL %q", raw))
+	case u.Scheme != "http" && u.Scheme != "https":
+		v.add(field, fmt.Sprintf("URL %q must use http or https", raw))
+	case u.Host == "":
+		v.add(field, fmt.Sprintf("URL %q has no host", raw))
+	}
+}
+
+func (v *validator) window(field, window string) {
+	if window == "" {
+		return
+	}
+	if d, err := parseWindowDuration(window); err != nil {
+		v.add(field, err.Error())
+	} else if d <= 0 {
+		v.add(field, fmt.Sprintf("window %q must be positive", window))
+	}
+}
+
+func nonNegative[T int | float64 | time.Duration](v *validator, field string, value T) {
+	if value < 0 {
+		v.add(field, fmt.Sprintf("must not be negative, got %v", value))
+	}
+}
+
+func (v *validator) auth(c Config) {
+	method := c.AuthType
+	if method == "" {
+		method = inferAuthType(c)
+	}
+	switch method {
+	case AuthNone:
+	case AuthBearer:
+		if c.APIToken == "" {
+			v.add("apiToken", "is required for authType bearer")
+		}
+	case AuthTokenFile:
+		if c.APITokenFile == "" {
+			v.add("apiTokenFile", "is required for authType tokenFile")
+		}
+	case AuthBasic:
+		if c.BasicAuthUsername == "" {
+			v.add("basicAuthUsername", "is required for authType basic")
+		}
+	case AuthOAuth2:
+		if c.OAuth2TokenURL == "" {
+			v.add("oauth2TokenUrl", "is required for authType oauth2")
+		}
+		if c.OAuth2ClientID == "" {
+			v.add("oauth2ClientId", "is required for authType oauth2")
+		}
+	default:
+		v.add("authType", fmt.Sprintf("unknown authType %q (use none, bearer, tokenFile, basic or oauth2)", c.AuthType))
+	}
+}
+
+// serverProblems checks the settings of the plugin's own gRPC server.
+func (c Config) serverProblems(v *validator) {
+	if c.ListenAddress != "" {
+		if _, _, err := net.SplitHostPort(c.ListenAddress); err != nil {
+			v.add("listenAddress", fmt.Sprintf("must be host:port: %v", err))
+		}
+	}
+	if (c.ServerCertFile == "") != (c.ServerKeyFile == "") {
+		field := "serverKeyFile"
+		if c.ServerCertFile == "" {
+			field = "serverCertFile"
+		}
+		v.add(field, "serverCertFile and serverKeyFile must be set together")
+	}
+	if c.ServerClientCAFile != "" && c.ServerCertFile == "" {
+		v.add("serverClientCaFile", "requires serverCertFile; mutual TLS needs server TLS")
+	}
+	if c.ServerAuthToken != "" && c.ServerCertFile == "" && !isLoopbackAddress(c.ListenAddress) {
+		v.add("serverAuthToken", "requires serverCertFile when listenAddress is not loopback, "+
+			"or the token crosses the network in cleartext")
+	}
+}
+
+// isLoopbackAddress reports whether a listen address accepts only local
+// connections. An empty address is the loopback default; one that is not
+// host:port is reported on its own and counts as loopback here.
+func isLoopbackAddress(addr string) bool {
+	if addr == "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,194 @@
+package kubecost //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// problemsOf returns the problems carried by a *ValidationError.
+func problemsOf(t *testing.T, err error) []Problem {
+	t.Helper()
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	return vErr.Problems
+}
+
+func findProblem(problems []Problem, field string) (Problem, bool) {
+	for _, p := range problems {
+		if p.Field == field {
+			return p, true
+		}
+	}
+	return Problem{}, false
+}
+
+func TestLoadConfigStrictFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, []byte(`baseUrl: http://kubecost:9090
+timeout: 7d
+tlsSkipVerfy: true
+rateBurst: lots
+`))
+
+	_, err := LoadConfigFromEnvOrFile(path)
+	problems := problemsOf(t, err)
+	if len(problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %d: %v", len(problems), err)
+	}
+
+	expected := map[string]int{"timeout": 2, "tlsSkipVerfy": 3, "rateBurst": 4}
+	for field, line := range expected {
+		p, ok := findProblem(problems, field)
+		if !ok {
+			t.Errorf("Expected a problem for %s in %v", field, problems)
+			continue
+		}
+		if p.Source != path || p.Line != line {
+			t.Errorf("%s: expected %s:%d, got %s:%d", field, path, line, p.Source, p.Line)
+		}
+	}
+	if p, _ := findProblem(problems, "tlsSkipVerfy"); p.Message != "unknown key" {
+		t.Errorf("Expected unknown key message, got %q", p.Message)
+	}
+}
+
+func TestLoadConfigSyntaxError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, []byte("baseUrl: http://kubecost:9090\ntimeout: [15s\n"))
+
+	problems := problemsOf(t, loadErr(LoadConfigFromEnvOrFile(path)))
+	if len(problems) != 1 || problems[0].Line == 0 {
+		t.Errorf("Expected one located syntax problem, got %v", problems)
+	}
+}
+
+func TestLoadConfigInvalidEnvironment(t *testing.T) {
+	t.Setenv("KUBECOST_TLS_SKIP_VERIFY", "yes")
+	t.Setenv("KUBECOST_MAX_CONCURRENCY", "four")
+
+	problems := problemsOf(t, loadErr(LoadConfigFromEnvOrFile("")))
+	for _, field := range []string{"tlsSkipVerify", "maxConcurrency"} {
+		p, ok := findProblem(problems, field)
+		if !ok || !strings.HasPrefix(p.Source, "KUBECOST_") {
+			t.Errorf("Expected %s problem attributed to its environment variable, got %v", field, problems)
+		}
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, []byte(`baseUrl: kubecost:9090
+defaultWindow: fortnight
+predictionWindow: 3d
+chunkFailurePolicy: retry
+tlsMinVersion: "1.1"
+`))
+
+	_, err := LoadValidatedConfig(LoadOptions{Path: path})
+	problems := problemsOf(t, err)
+	expected := map[string]int{
+		"baseUrl":            1,
+		"defaultWindow":      2,
+		"clusterId":          3,
+		"chunkFailurePolicy": 4,
+		"tlsMinVersion":      5,
+	}
+	for field, line := range expected {
+		p, ok := findProblem(problems, field)
+		if !ok {
+			t.Errorf("Expected a problem for %s in %v", field, err)
+			continue
+		}
+		if p.Line != line {
+			t.Errorf("%s: expected line %d, got %d", field, line, p.Line)
+		}
+	}
+	if !strings.Contains(err.Error(), path+":4: chunkFailurePolicy:") {
+		t.Errorf("Expected file:line locations in error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Config{BaseURL: "https://kubecost.example.com", DefaultWindow: "month", PredictionWindow: "2d"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+
+	problems := problemsOf(t, Config{}.Validate())
+	if p, ok := findProblem(problems, "baseUrl"); !ok || p.Source != "" {
+		t.Errorf("Expected unlocated baseUrl problem, got %v", problems)
+	}
+
+	problems = problemsOf(t, Config{
+		BaseURL: "http://kubecost", AuthType: AuthOAuth2, RateLimit: -1, SizingWindow: "3x", SizingTargetUtilization: 1.5,
+	}.Validate())
+	for _, field := range []string{"oauth2TokenUrl", "oauth2ClientId", "rateLimit", "sizingWindow", "sizingTargetUtilization"} {
+		if _, ok := findProblem(problems, field); !ok {
+			t.Errorf("Expected a problem for %s in %v", field, problems)
+		}
+	}
+
+	problems = problemsOf(t, Config{
+		BaseURL: "http://kubecost", ListenAddress: "50051", ServerKeyFile: "key.pem", ServerClientCAFile: "ca.pem",
+	}.Validate())
+	for _, field := range []string{"listenAddress", "serverCertFile", "serverClientCaFile"} {
+		if _, ok := findProblem(problems, field); !ok {
+			t.Errorf("Expected a problem for %s in %v", field, problems)
+		}
+	}
+
+	for addr, wantProblem := range map[string]bool{
+		"":                false,
+		"127.0.0.1:50051": false,
+		"[::1]:50051":     false,
+		"localhost:50051": false,
+		":50051":          true,
+		"0.0.0.0:50051":   true,
+		"10.0.0.5:50051":  true,
+	} {
+		err := Config{BaseURL: "http://kubecost", ListenAddress: addr, ServerAuthToken: "token"}.Validate()
+		if (err != nil) != wantProblem {
+			t.Errorf("listenAddress %q: expected a cleartext token problem %v, got %v", addr, wantProblem, err)
+		} else if err != nil {
+			if _, ok := findProblem(problemsOf(t, err), "serverAuthToken"); !ok {
+				t.Errorf("listenAddress %q: expected a serverAuthToken problem, got %v", addr, err)
+			}
+		}
+	}
+	withTLS := Config{
+		BaseURL: "http://kubecost", ListenAddress: ":50051", ServerAuthToken: "token",
+		ServerCertFile: "cert.pem", ServerKeyFile: "key.pem",
+	}
+	if err := withTLS.Validate(); err != nil {
+		t.Errorf("Expected a token over TLS to be valid, got %v", err)
+	}
+}
+
+func loadErr(_ Config, err error) error {
+	return err
+}
+
+func TestConfigFieldsCoverConfig(t *testing.T) {
+	documented := map[string]bool{}
+	for _, f := range ConfigFields() {
+		if documented[f.Key] {
+			t.Errorf("%s is documented twice", f.Key)
+		}
+		documented[f.Key] = true
+		if _, ok := configKeys[f.Key]; !ok {
+			t.Errorf("%s is documented but is not a Config key", f.Key)
+		}
+		if f.Env == "" || f.Description == "" {
+			t.Errorf("%s needs an environment variable and a description", f.Key)
+		}
+	}
+	for key := range configKeys {
+		if !documented[key] {
+			t.Errorf("Config key %s is missing from configFields", key)
+		}
+	}
+}
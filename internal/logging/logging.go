@@ -0,0 +1,213 @@
+// Package logging configures the plugin's structured logger. Every record
+// passes through a Redactor, so credentials never reach the log even when they
+// appear in error messages or upstream response bodies.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// sensitiveKeys are attribute keys whose values are always masked, compared
+// case-insensitively.
+var sensitiveKeys = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"password":            true,
+	"secret":              true,
+	"token":               true,
+}
+
+// ParseLevel parses debug, info, warn or error; empty means info.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", s)
+	}
+	return l, nil
+}
+
+// New returns a logger writing text or JSON records at level or above to w.
+// Attributes named in secretKeys, such as the configuration's credential keys,
+// are masked in addition to the built-in sensitive keys, and every string
+// value is passed through r.
+func New(w io.Writer, format string, level slog.Leveler, r *Redactor, secretKeys ...string) (*slog.Logger, error) {
+	keys := make(map[string]bool, len(sensitiveKeys)+len(secretKeys))
+	for k := range sensitiveKeys {
+		keys[k] = true
+	}
+	for _, k := range secretKeys {
+		keys[strings.ToLower(k)] = true
+	}
+	opts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			return redactAttr(a, keys, r)
+		},
+	}
+	switch format {
+	case FormatText, "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q (use text or json)", format)
+}
+
+func redactAttr(a slog.Attr, keys map[string]bool, r *Redactor) slog.Attr {
+	if keys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+	v := a.Value.Resolve()
+	switch v.Kind() { //nolint:exhaustive // other kinds carry no text
+	case slog.KindString:
+		return slog.String(a.Key, r.String(v.String()))
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case http.Header:
+			return slog.Any(a.Key, redactHeader(x, keys, r))
+		case error:
+			return slog.String(a.Key, r.String(x.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, r.String(x.String()))
+		}
+		// Structs such as RPC requests may hold credentials in any field; slices
+		// and maps keep their structure with their strings redacted
+		rv := reflect.ValueOf(v.Any())
+		switch reflect.Indirect(rv).Kind() { //nolint:exhaustive // other kinds carry no text
+		case reflect.Struct:
+			return slog.String(a.Key, r.String(fmt.Sprintf("%+v", v.Any())))
+		case reflect.Slice, reflect.Map:
+			return slog.Any(a.Key, redactValue(rv, keys, r).Interface())
+		}
+	}
+	return a
+}
+
+// redactValue returns a copy of v with every string it holds passed through r,
+// walking nested slices, maps, pointers and interfaces. Map values under sensitive keys
+// are masked. Values of other kinds are returned as they are.
+func redactValue(v reflect.Value, keys map[string]bool, r *Redactor) reflect.Value {
+	switch v.Kind() { //nolint:exhaustive // other kinds carry no text
+	case reflect.String:
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(r.String(v.String()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(redactValue(v.Elem(), keys, r))
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(redactValue(v.Elem(), keys, r))
+		return out
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(redactValue(v.Index(i), keys, r))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		masked := reflect.ValueOf(Redacted)
+		elem := v.Type().Elem()
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			k := it.Key()
+			if k.Kind() == reflect.String && keys[strings.ToLower(k.String())] && masked.CanConvert(elem) {
+				out.SetMapIndex(k, masked.Convert(elem))
+				continue
+			}
+			out.SetMapIndex(k, redactValue(it.Value(), keys, r))
+		}
+		return out
+	}
+	return v
+}
+
+func redactHeader(h http.Header, keys map[string]bool, r *Redactor) http.Header {
+	out := make(http.Header, len(h))
+	for k, vs := range h {
+		masked := make([]string, len(vs))
+		for i, v := range vs {
+			if keys[strings.ToLower(k)] {
+				masked[i] = Redacted
+			} else {
+				masked[i] = r.String(v)
+			}
+		}
+		out[k] = masked
+	}
+	return out
+}
+
+type scopeKey struct{}
+
+// scope holds the request-scoped logger and the time spent waiting on Kubecost
+// for one RPC.
+type scope struct {
+	logger   *slog.Logger
+	upstream *atomic.Int64
+}
+
+// With returns a context whose logger adds args, key-value pairs as accepted by
+// slog.Logger.With, to every record. Calls on the same request accumulate.
+func With(ctx context.Context, args ...any) context.Context {
+	s := &scope{logger: FromContext(ctx).With(args...), upstream: new(atomic.Int64)}
+	if parent, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.upstream = parent.upstream
+	}
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// FromContext returns the request-scoped logger, or slog.Default.
+func FromContext(ctx context.Context) *slog.Logger {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		return s.logger
+	}
+	return slog.Default()
+}
+
+// ObserveUpstream adds d to the Kubecost latency recorded for the request.
+func ObserveUpstream(ctx context.Context, d time.Duration) {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.upstream.Add(int64(d))
+	}
+}
+
+// UpstreamLatency returns the total Kubecost latency recorded for the request.
+func UpstreamLatency(ctx context.Context) time.Duration {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		return time.Duration(s.upstream.Load())
+	}
+	return 0
+}
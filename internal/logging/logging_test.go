@@ -0,0 +1,129 @@
+package logging //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRedactsRecords(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, slog.LevelInfo, NewRedactor("configured-secret"), "apiToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("request",
+		"apiToken", "anything",
+		"header", http.Header{"Authorization": {"Bearer abcdefghijkl"}, "Accept": {"application/json"}},
+		"error", errors.New("kubecost said configured-secret is invalid"),
+		"request", struct{ Spec string }{Spec: "password: configured-secret"},
+		"windows", []string{"1d", "2d"},
+		"counts", map[string]int{"pods": 2},
+	)
+	logger.Debug("hidden below info")
+
+	var record map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["apiToken"] != Redacted {
+		t.Errorf("Expected secret key to be masked, got %v", record["apiToken"])
+	}
+	header, _ := record["header"].(map[string]any)
+	if auth, _ := header["Authorization"].([]any); len(auth) != 1 || auth[0] != Redacted {
+		t.Errorf("Expected Authorization header to be masked, got %v", header)
+	}
+	if accept, _ := header["Accept"].([]any); len(accept) != 1 || accept[0] != "application/json" {
+		t.Errorf("Expected other headers to be kept, got %v", header)
+	}
+	if windows, _ := record["windows"].([]any); len(windows) != 2 {
+		t.Errorf("Expected slices to stay JSON arrays, got %v", record["windows"])
+	}
+	if counts, _ := record["counts"].(map[string]any); counts["pods"] != 2.0 {
+		t.Errorf("Expected maps to stay JSON objects, got %v", record["counts"])
+	}
+	if strings.Contains(buf.String(), "configured-secret") {
+		t.Errorf("Expected configured secret to be masked, got %s", buf.String())
+	}
+}
+
+func TestNewRedactsSlicesAndMaps(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, slog.LevelInfo, NewRedactor("configured-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("request",
+		"headers", map[string]string{"X-Scope-OrgID": "configured-secret", "Token": "abc", "Accept": "json"},
+		"args", []string{"-token", "configured-secret"},
+		"argsRef", &[]string{"configured-secret"},
+		"nested", []any{map[string]any{"spec": []any{"configured-secret", 2}}},
+	)
+
+	var record map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", buf.String(), err)
+	}
+	headers, _ := record["headers"].(map[string]any)
+	if headers["X-Scope-OrgID"] != Redacted || headers["Token"] != Redacted || headers["Accept"] != "json" {
+		t.Errorf("Expected secrets in map values to be masked, got %v", record["headers"])
+	}
+	if args, _ := record["args"].([]any); len(args) != 2 || args[0] != "-token" || args[1] != Redacted {
+		t.Errorf("Expected secrets in slice elements to be masked, got %v", record["args"])
+	}
+	nested, _ := record["nested"].([]any)
+	if len(nested) != 1 {
+		t.Fatalf("Expected nested to stay a JSON array, got %v", record["nested"])
+	}
+	inner, _ := nested[0].(map[string]any)
+	if spec, _ := inner["spec"].([]any); len(spec) != 2 || spec[0] != Redacted || spec[1] != 2.0 {
+		t.Errorf("Expected nested values to be walked, got %v", record["nested"])
+	}
+	if strings.Contains(buf.String(), "configured-secret") {
+		t.Errorf("Expected configured secret to be masked, got %s", buf.String())
+	}
+}
+
+func TestNewRejectsUnknownFormat(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", slog.LevelInfo, nil); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestRequestScope(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatText, slog.LevelInfo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("Expected the default logger outside a request")
+	}
+
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	ctx := With(context.Background(), "rpc", "GetActualCost")
+	inner := With(ctx, "window", "1d")
+	ObserveUpstream(inner, 2*time.Second)
+	ObserveUpstream(ctx, time.Second)
+	FromContext(inner).Info("kubecost request")
+
+	if got := UpstreamLatency(ctx); got != 3*time.Second {
+		t.Errorf("Expected latency shared across the request, got %v", got)
+	}
+	if out := buf.String(); !strings.Contains(out, "rpc=GetActualCost") || !strings.Contains(out, "window=1d") {
+		t.Errorf("Expected request attributes in %q", out)
+	}
+}
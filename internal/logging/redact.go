@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Redacted replaces credentials in log output and error messages.
+const Redacted = "[REDACTED]"
+
+// minSecretLen keeps very short configured secrets from masking unrelated text.
+const minSecretLen = 4
+
+var (
+	// authScheme matches HTTP Authorization values such as "Bearer abc123xyz".
+	authScheme = regexp.MustCompile(`\b(Bearer|Basic)\s+[A-Za-z0-9\-._~+/]{8,}=*`)
+	// urlPassword matches the password in URL user info, e.g. http://u:p@host.
+	urlPassword = regexp.MustCompile(`(://[^:/@\s]*):[^@/\s]+@`)
+	// secretParam matches token=..., "password": "..." and similar pairs in
+	// query strings, environment listings and JSON.
+	secretParam = regexp.MustCompile(
+		`(?i)(^|[^a-z0-9])((?:api[_-]?|access[_-]?|refresh[_-]?|id[_-]?)?token|password|passwd|(?:client[_-]?)?secret|api[_-]?key)` +
+			`(=|"\s*:\s*")[^"&\s,;}]+`)
+)
+
+// Redactor masks credentials: well-known patterns such as Authorization values
+// and URL passwords, plus the secret values of the running configuration. It is
+// safe for concurrent use; the secrets are replaced when the configuration is
+// reloaded.
+type Redactor struct {
+	secrets atomic.Pointer[[]string]
+}
+
+// NewRedactor returns a redactor masking the given secret values.
+func NewRedactor(secrets ...string) *Redactor {
+	r := &Redactor{}
+	r.SetSecrets(secrets...)
+	return r
+}
+
+// SetSecrets replaces the secret values r masks. Empty and very short values
+// are ignored.
+func (r *Redactor) SetSecrets(secrets ...string) {
+	kept := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		if len(s) >= minSecretLen {
+			kept = append(kept, s)
+		}
+	}
+	// Longest first, so a secret containing another is masked whole
+	slices.SortFunc(kept, func(a, b string) int { return len(b) - len(a) })
+	r.secrets.Store(&kept)
+}
+
+// String returns s with credentials masked. A nil Redactor masks only the
+// well-known patterns.
+func (r *Redactor) String(s string) string {
+	s = Redact(s)
+	if r == nil {
+		return s
+	}
+	if secrets := r.secrets.Load(); secrets != nil {
+		for _, secret := range *secrets {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	return s
+}
+
+// Redact masks Authorization values, URL passwords and token or password
+// parameters in s.
+func Redact(s string) string {
+	s = authScheme.ReplaceAllString(s, "$1 "+Redacted)
+	s = urlPassword.ReplaceAllString(s, "$1:xxxxx@")
+	return secretParam.ReplaceAllString(s, "$1$2$3"+Redacted)
+}
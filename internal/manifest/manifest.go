@@ -0,0 +1,226 @@
+// Package manifest generates plugin.manifest.json and the JSON Schema of the
+// YAML configuration from the Go types, so neither drifts from the code.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/server"
+	"github.com/rshade/pulumicost-plugin-kubecost/pkg/version"
+)
+
+// Files generated at the repository root.
+const (
+	ManifestFile = "plugin.manifest.json"
+	SchemaFile   = "config.schema.json"
+)
+
+const (
+	description = "Kubecost plugin for Pulumicost - provides actual and projected cost for Kubernetes resources"
+	executable  = "pulumicost-kubecost"
+	schemaID    = "https://github.com/rshade/pulumicost-plugin-kubecost/" + SchemaFile
+)
+
+// durationPattern matches Go durations such as "15s" or "1h30m".
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+// envReferencePattern matches a value that is entirely ${VAR} or ${VAR:-default}.
+const envReferencePattern = `^\$\{[A-Za-z_][A-Za-z0-9_]*(:-[^}]*)?\}$`
+
+type pluginManifest struct {
+	Name               string   `json:"name"`
+	Version            string   `json:"version"`
+	Type               string   `json:"type"`
+	Description        string   `json:"description"`
+	Executable         string   `json:"executable"`
+	Protocol           string   `json:"protocol"`
+	SupportedResources []string `json:"supported_resources"`
+	ConfigSchema       string   `json:"config_schema"`
+	Configuration      object   `json:"configuration"`
+}
+
+type setting struct {
+	Type        string   `json:"type"`
+	Format      string   `json:"format,omitempty"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Default     any      `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Env         string   `json:"env"`
+	Sensitive   bool     `json:"sensitive,omitempty"`
+}
+
+// Manifest returns the contents of plugin.manifest.json.
+func Manifest() ([]byte, error) {
+	m := pluginManifest{
+		Name:               server.Name,
+		Version:            version.GetVersionInfo().Version,
+		Type:               "costsource",
+		Description:        description,
+		Executable:         executable,
+		Protocol:           "grpc",
+		SupportedResources: server.SupportedResources(),
+		ConfigSchema:       SchemaFile,
+	}
+	for _, f := range kubecost.ConfigFields() {
+		s := setting{
+			Type:        f.Type,
+			Description: f.Description,
+			Required:    f.Required,
+			Default:     f.Default,
+			Enum:        f.Enum,
+			Env:         f.Env,
+			Sensitive:   f.Secret,
+		}
+		if f.Duration {
+			s.Format = "duration"
+		}
+		m.Configuration.set(f.Key, s)
+	}
+	return marshal(m)
+}
+
+// Schema returns the JSON Schema of the YAML config file, including named
+// profiles and ${VAR} references in place of non-string values.
+func Schema() ([]byte, error) {
+	var settings, profileSettings object
+	for _, f := range kubecost.ConfigFields() {
+		s := fieldSchema(f)
+		settings.set(f.Key, s)
+		if f.Key != "profile" {
+			profileSettings.set(f.Key, s)
+		}
+	}
+	settings.set("profiles", object{}.
+		with("type", "object").
+		with("description", "Named partial configurations applied over the top-level keys").
+		with("additionalProperties", object{}.with("$ref", "#/$defs/profile")))
+
+	schema := object{}.
+		with("$schema", "https://json-schema.org/draft/2020-12/schema").
+		with("$id", schemaID).
+		with("title", "pulumicost-kubecost configuration").
+		with("type", "object").
+		with("properties", settings).
+		with("additionalProperties", false)
+	schema = schema.with("$defs", object{}.
+		with("profile", object{}.
+			with("type", "object").
+			with("properties", profileSettings).
+			with("additionalProperties", false)).
+		with("envReference", object{}.
+			with("type", "string").
+			with("description", "An environment variable reference, ${VAR} or ${VAR:-default}").
+			with("pattern", envReferencePattern)))
+	return marshal(schema)
+}
+
+func fieldSchema(f kubecost.FieldInfo) object {
+	var s object
+	switch {
+	case f.Duration:
+		s = object{}.with("type", "string").with("pattern", durationPattern)
+	case len(f.Enum) > 0:
+		s = object{}.with("enum", f.Enum)
+	case f.Type == "array":
+		s = object{}.with("type", "array").with("items", object{}.with("type", "string"))
+	case f.Type == "object":
+		s = object{}.with("type", "object").with("additionalProperties", object{}.with("type", "string"))
+	default:
+		s = object{}.with("type", f.Type)
+	}
+	if f.Duration || len(f.Enum) > 0 || (f.Type != "string" && f.Type != "array" && f.Type != "object") {
+		s = object{}.with("anyOf", []object{s, object{}.with("$ref", "#/$defs/envReference")})
+	}
+	s = s.with("description", f.Description)
+	if f.Default != nil {
+		s = s.with("default", f.Default)
+	}
+	if f.Secret {
+		s = s.with("writeOnly", true)
+	}
+	return s
+}
+
+// Write generates both files into dir.
+func Write(dir string) error {
+	m, err := Manifest()
+	if err != nil {
+		return err
+	}
+	s, err := Schema()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), m, 0o644); err != nil { //nolint:gosec // published, not secret
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, SchemaFile), s, 0o644) //nolint:gosec // published, not secret
+}
+
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// object is a JSON object that keeps its keys in insertion order, so the
+// generated files read in the same order as the Config fields.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *object) set(key string, v any) {
+	if o.values == nil {
+		o.values = map[string]any{}
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+func (o object) with(key string, v any) object {
+	o.keys = append([]string(nil), o.keys...)
+	values := make(map[string]any, len(o.values)+1)
+	for k, val := range o.values {
+		values[k] = val
+	}
+	o.values = values
+	o.set(key, v)
+	return o
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(o.values[k]); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1) // Encode appends a newline
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package manifest //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/server"
+)
+
+// TestCheckedInFilesAreCurrent fails when plugin.manifest.json or
+// config.schema.json no longer match the code; run `make manifest`.
+func TestCheckedInFilesAreCurrent(t *testing.T) {
+	for file, generate := range map[string]func() ([]byte, error){
+		ManifestFile: Manifest,
+		SchemaFile:   Schema,
+	} {
+		want, err := generate()
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		got, err := os.ReadFile(filepath.Join("..", "..", file))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is stale, regenerate it with `make manifest`", file)
+		}
+	}
+}
+
+func TestManifest(t *testing.T) {
+	b, err := Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Name               string                    `json:"name"`
+		SupportedResources []string                  `json:"supported_resources"`
+		Configuration      map[string]map[string]any `json:"configuration"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("Manifest is not valid JSON: %v", err)
+	}
+	if m.Name != server.Name || len(m.SupportedResources) != len(server.SupportedResources()) {
+		t.Errorf("Expected name and resources from the server, got %s %v", m.Name, m.SupportedResources)
+	}
+	for _, key := range []string{"clusterId", "defaultNamespace", "predictionWindow"} {
+		if _, ok := m.Configuration[key]; !ok {
+			t.Errorf("Expected %s in configuration", key)
+		}
+	}
+	if m.Configuration["baseUrl"]["required"] != true {
+		t.Errorf("Expected baseUrl to be required, got %v", m.Configuration["baseUrl"])
+	}
+	if m.Configuration["timeout"]["default"] != "15s" || m.Configuration["timeout"]["format"] != "duration" {
+		t.Errorf("Expected timeout duration defaulting to 15s, got %v", m.Configuration["timeout"])
+	}
+	if m.Configuration["apiToken"]["sensitive"] != true {
+		t.Errorf("Expected apiToken to be sensitive, got %v", m.Configuration["apiToken"])
+	}
+}
+
+func TestSchema(t *testing.T) {
+	b, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s struct {
+		Properties map[string]any `json:"properties"`
+		Defs       struct {
+			Profile struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"profile"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	if _, ok := s.Properties["profiles"]; !ok {
+		t.Error("Expected profiles in the schema")
+	}
+	if _, ok := s.Defs.Profile.Properties["profile"]; ok {
+		t.Error("Profiles must not select another profile")
+	}
+	if len(s.Defs.Profile.Properties) != len(s.Properties)-2 {
+		t.Errorf("Expected profiles to accept every setting, got %d of %d", len(s.Defs.Profile.Properties), len(s.Properties))
+	}
+}
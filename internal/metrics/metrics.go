@@ -0,0 +1,140 @@
+// Package metrics defines the plugin's Prometheus metrics: RPCs served, calls
+// to Kubecost and cache lookups. They are always recorded and exposed only
+// when a metrics listener is configured.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pulumicost_kubecost"
+
+// Path is where the listener serves metrics.
+const Path = "/metrics"
+
+// readHeaderTimeout bounds slow scrapers holding connections open.
+const readHeaderTimeout = 10 * time.Second
+
+var registry = prometheus.NewRegistry()
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_requests_total",
+		Help:      "CostSource RPCs handled, by method and gRPC status code.",
+	}, []string{"method", "code"})
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "CostSource RPC latency, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Requests sent to Kubecost, by endpoint and HTTP status; status is \"error\" when no response arrived.",
+	}, []string{"endpoint", "status"})
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Time until Kubecost response headers arrived, by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+	upstreamBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_response_bytes",
+		Help:      "Kubecost response body size as received, by endpoint.",
+		Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 8), // 1KiB to 16MiB
+	}, []string{"endpoint"})
+
+	// The client neither retries Kubecost requests nor has a circuit breaker
+	// yet; these stay at zero so dashboards and alerts can already use them.
+	upstreamRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_retries_total",
+		Help:      "Kubecost requests retried after a failure; always 0 until the client retries.",
+	})
+	upstreamBreakerState = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_circuit_breaker_state",
+		Help:      "Kubecost circuit breaker state: 0 closed, 1 half-open, 2 open; always 0 until the client has a breaker.",
+	})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+)
+
+func init() {
+	registry.MustRegister(
+		rpcRequests, rpcDuration,
+		upstreamRequests, upstreamDuration, upstreamBytes,
+		upstreamRetries, upstreamBreakerState,
+		cacheLookups,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveRPC records a completed RPC with its gRPC status code name.
+func ObserveRPC(method, code string, d time.Duration) {
+	rpcRequests.WithLabelValues(method, code).Inc()
+	rpcDuration.WithLabelValues(method).Observe(d.Seconds())
+}
+
+// ObserveUpstream records a Kubecost request; status is 0 when the request
+// failed without a response.
+func ObserveUpstream(endpoint string, status int, d time.Duration) {
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+	}
+	upstreamRequests.WithLabelValues(endpoint, code).Inc()
+	upstreamDuration.WithLabelValues(endpoint).Observe(d.Seconds())
+}
+
+// ObserveUpstreamBytes records the size of a Kubecost response body.
+func ObserveUpstreamBytes(endpoint string, n int) {
+	upstreamBytes.WithLabelValues(endpoint).Observe(float64(n))
+}
+
+// CacheLookup records a hit or miss in the named cache.
+func CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// Handler serves the plugin's metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Serve exposes metrics on lis until ctx is done.
+func Serve(ctx context.Context, lis net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package metrics //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeExposesMetrics(t *testing.T) {
+	ObserveRPC("GetActualCost", "OK", 120*time.Millisecond)
+	ObserveUpstream("/model/allocation", http.StatusOK, 80*time.Millisecond)
+	ObserveUpstream("/model/allocation", 0, time.Second)
+	ObserveUpstreamBytes("/model/allocation", 4096)
+	CacheLookup("oauth2_token", true)
+	CacheLookup("oauth2_token", false)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, lis) }()
+
+	resp, err := http.Get("http://" + lis.Addr().String() + Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	for _, want := range []string{
+		`pulumicost_kubecost_rpc_requests_total{code="OK",method="GetActualCost"} 1`,
+		`pulumicost_kubecost_upstream_requests_total{endpoint="/model/allocation",status="200"} 1`,
+		`pulumicost_kubecost_upstream_requests_total{endpoint="/model/allocation",status="error"} 1`,
+		`pulumicost_kubecost_upstream_response_bytes_count{endpoint="/model/allocation"} 1`,
+		`pulumicost_kubecost_cache_lookups_total{cache="oauth2_token",result="hit"} 1`,
+		`pulumicost_kubecost_cache_lookups_total{cache="oauth2_token",result="miss"} 1`,
+		`pulumicost_kubecost_upstream_retries_total 0`,
+		`pulumicost_kubecost_upstream_circuit_breaker_state 0`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %s in metrics output", want)
+		}
+	}
+
+	cancel()
+	if err = <-done; err != nil {
+		t.Errorf("Expected a clean stop, got %v", err)
+	}
+}
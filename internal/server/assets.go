@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// assetCostSource marks results taken from Kubecost assets rather than
+// allocations.
+const assetCostSource = "kubecost-assets"
+
+// assetKind describes a resource kind costed from Kubecost assets rather than
+// allocations.
+type assetKind struct {
+	assetType string
+	// ref returns the resource an asset belongs to.
+	ref func(kubecost.Asset) resourceRef
+	// filter narrows the assets query to a single resource; nil fetches every
+	// asset of the type.
+	filter func(resourceRef) map[string]string
+	// usage returns the asset's usage amount and unit.
+	usage func(kubecost.Asset) (float64, string)
+}
+
+// assetKinds are the resource kinds costed from assets. A node's cost is what
+// the node itself cost, idle capacity included, rather than the sum of the
+// pods that ran on it. A PVC's cost is that of the volume bound to it.
+var assetKinds = map[string]assetKind{
+	dimNode: {
+		assetType: kubecost.AssetNode,
+		ref: func(a kubecost.Asset) resourceRef {
+			return resourceRef{kind: dimNode, name: a.Properties.Name}
+		},
+		filter: func(r resourceRef) map[string]string { return map[string]string{"name": r.name} },
+		usage:  func(a kubecost.Asset) (float64, string) { return a.Hours(), "hours" },
+	},
+	dimPVC: {
+		assetType: kubecost.AssetDisk,
+		ref: func(a kubecost.Asset) resourceRef {
+			return resourceRef{kind: dimPVC, namespace: a.ClaimNS, name: a.ClaimName}
+		},
+		filter: func(r resourceRef) map[string]string {
+			return map[string]string{"claimNamespace": r.namespace, "claimName": r.name}
+		},
+		usage: func(a kubecost.Asset) (float64, string) { return a.GiBHours(), "GiB-hours" },
+	},
+}
+
+// costedFromAssets reports whether resources of a kind are costed from assets.
+func costedFromAssets(kind string) bool {
+	_, ok := assetKinds[kind]
+	return ok
+}
+
+// assetCosts returns the per-window costs of refs from their Kubecost asset
+// records, with one assets query per kind. Assets of the same resource, such
+// as nodes sharing a name across clusters, are summed.
+func assetCosts(
+	ctx context.Context,
+	cli *kubecost.Client,
+	window string,
+	refs []resourceRef,
+) (map[resourceRef][]*ActualCostResult, error) {
+	byKind := map[string][]resourceRef{}
+	for _, r := range refs {
+		byKind[r.kind] = append(byKind[r.kind], r)
+	}
+
+	out := make(map[resourceRef][]*ActualCostResult, len(refs))
+	for _, dim := range []string{dimNode, dimPVC} {
+		refs := byKind[dim]
+		if len(refs) == 0 {
+			continue
+		}
+		kind := assetKinds[dim]
+		q := kubecost.AssetsQuery{Window: window, Types: []string{kind.assetType}}
+		if len(refs) == 1 && kind.filter != nil {
+			q.Filter = kind.filter(refs[0])
+		}
+		resp, err := cli.Assets(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		byTime := make(map[resourceRef]map[string]*ActualCostResult, len(refs))
+		for _, r := range refs {
+			byTime[r] = map[string]*ActualCostResult{}
+		}
+		for _, set := range resp.Data {
+			for _, asset := range set {
+				ref := kind.ref(asset)
+				seen, wanted := byTime[ref]
+				if asset.Type != kind.assetType || !wanted {
+					continue
+				}
+				start := asset.Start
+				if start == "" {
+					start = asset.Window.Start
+				}
+				amount, unit := kind.usage(asset)
+				if r, ok := seen[start]; ok {
+					r.Cost += asset.TotalCost
+					r.UsageAmount += amount
+					continue
+				}
+				r := toCostResult(start, asset.TotalCost, amount, unit, assetCostSource)
+				seen[start] = r
+				out[ref] = append(out[ref], r)
+			}
+		}
+	}
+	return out, nil
+}
+
+// toCostResult returns the ActualCostResult of a Kubecost record starting at
+// start.
+func toCostResult(start string, cost, usage float64, unit, source string) *ActualCostResult {
+	ts, _ := time.Parse(time.RFC3339, start)
+	return &ActualCostResult{
+		Timestamp:   timestamppb.New(ts),
+		Cost:        cost,
+		UsageAmount: usage,
+		UsageUnit:   unit,
+		Source:      source,
+	}
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+// TODO: Replace these stubs when pulumicost-spec protobuf definitions are available
+type BatchActualCostQuery struct {
+	ResourceIDs []string
+	Start, End  string
+}
+type ResourceActualCost struct {
+	ResourceID string
+	Results    []*ActualCostResult
+	Error      string
+}
+type BatchActualCostResponse struct{ Resources []*ResourceActualCost }
+
+// Aggregation dimensions in the order they are passed to Kubecost.
+const (
+	dimNamespace  = "namespace"
+	dimController = "controller"
+	dimPod        = "pod"
+	dimNode       = "node"
+	dimPVC        = "pvc"
+	dimCloud      = "cloud"
+	dimCloudLabel = "cloud-label"
+)
+
+// resourceRef is a parsed ActualCostQuery.ResourceID. For cloud labels,
+// namespace holds the label key and name its value.
+type resourceRef struct {
+	kind, namespace, name string
+}
+
+// parseResourceRef parses IDs like "namespace/<ns>", "pod/<ns>/<pod>",
+// "controller/<ns>/<ctrl>", "node/<node>", "pvc/<ns>/<claim>",
+// "cloud/<providerID>" and "cloud-label/<key>=<value>". Provider IDs may
+// themselves contain slashes.
+func parseResourceRef(resourceID string) (resourceRef, error) {
+	if id, ok := strings.CutPrefix(resourceID, dimCloud+"/"); ok && id != "" {
+		return resourceRef{kind: dimCloud, name: id}, nil
+	}
+	if label, ok := strings.CutPrefix(resourceID, dimCloudLabel+"/"); ok {
+		if key, value, ok := strings.Cut(label, "="); ok && key != "" && value != "" {
+			return resourceRef{kind: dimCloudLabel, namespace: key, name: value}, nil
+		}
+	}
+	parts := strings.Split(resourceID, "/")
+	switch {
+	case parts[0] == dimNamespace && len(parts) == minNamespaceParts:
+		return resourceRef{kind: dimNamespace, namespace: parts[1]}, nil
+	case parts[0] == dimPod && len(parts) == minPodParts:
+		return resourceRef{kind: dimPod, namespace: parts[1], name: parts[2]}, nil
+	case parts[0] == dimController && len(parts) == minControllerParts:
+		return resourceRef{kind: dimController, namespace: parts[1], name: parts[2]}, nil
+	case parts[0] == dimNode && len(parts) == minNodeParts:
+		return resourceRef{kind: dimNode, name: parts[1]}, nil
+	case parts[0] == dimPVC && len(parts) == minPVCParts:
+		return resourceRef{kind: dimPVC, namespace: parts[1], name: parts[2]}, nil
+	}
+	return resourceRef{}, fmt.Errorf("unsupported resource ID %q", resourceID)
+}
+
+// matches reports whether an allocation aggregated at least as finely as this
+// reference belongs to the referenced resource.
+func (r resourceRef) matches(p kubecost.AllocationProperties) bool {
+	switch r.kind {
+	case dimNamespace:
+		return p.Namespace == r.namespace
+	case dimController:
+		return p.Namespace == r.namespace && p.Controller == r.name
+	case dimPod:
+		return p.Namespace == r.namespace && p.Pod == r.name
+	}
+	return false
+}
+
+// costedDirectly reports whether resources of a kind are costed from their own
+// Kubecost asset or cloud cost records rather than from allocations.
+func costedDirectly(kind string) bool {
+	return costedFromAssets(kind) || kind == dimCloud || kind == dimCloudLabel
+}
+
+// directRef parses a resource ID naming a resource that is costed directly.
+func directRef(resourceID string) (resourceRef, bool) {
+	ref, err := parseResourceRef(resourceID)
+	if err != nil || !costedDirectly(ref.kind) {
+		return resourceRef{}, false
+	}
+	return ref, true
+}
+
+// directCosts returns the per-window costs of resources costed directly, see
+// assetCosts and cloudCosts.
+func directCosts(
+	ctx context.Context,
+	cli *kubecost.Client,
+	window string,
+	refs []resourceRef,
+) (map[resourceRef][]*ActualCostResult, error) {
+	var assets, cloud []resourceRef
+	for _, r := range refs {
+		if costedFromAssets(r.kind) {
+			assets = append(assets, r)
+		} else {
+			cloud = append(cloud, r)
+		}
+	}
+	out, err := assetCosts(ctx, cli, window, assets)
+	if err != nil {
+		return nil, err
+	}
+	cloudOut, err := cloudCosts(ctx, cli, window, cloud)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(out, cloudOut)
+	return out, nil
+}
+
+// batchAggregation returns the coarsest Kubecost aggregation from which every
+// referenced namespace, controller and pod can be reconstructed.
+func batchAggregation(refs []resourceRef) []string {
+	need := map[string]bool{}
+	for _, r := range refs {
+		need[r.kind] = true
+		need[dimNamespace] = true
+	}
+
+	var aggregate []string
+	for _, dim := range []string{dimNamespace, dimController, dimPod} {
+		if need[dim] {
+			aggregate = append(aggregate, dim)
+		}
+	}
+	return aggregate
+}
+
+// batchNamespaces returns the namespaces of refs, each once, in order.
+func batchNamespaces(refs []resourceRef) []string {
+	var out []string
+	for _, r := range refs {
+		if !slices.Contains(out, r.namespace) {
+			out = append(out, r.namespace)
+		}
+	}
+	return out
+}
+
+// BatchActualCost returns actual costs for many resources over one window using
+// a single aggregated Kubecost allocation query, limited to the namespaces the
+// resources live in, and demultiplexes the aggregated entries back into
+// per-resource results. Nodes, PVCs and cloud resources are costed from their
+// Kubecost asset and cloud cost records instead, like GetActualCost does.
+// Resource IDs that cannot be parsed are reported individually instead of
+// failing the batch.
+func (s *KubecostServer) BatchActualCost(
+	ctx context.Context,
+	q *BatchActualCostQuery,
+) (*BatchActualCostResponse, error) {
+	out := &BatchActualCostResponse{}
+	type target struct {
+		ref    resourceRef
+		result *ResourceActualCost
+		byTime map[string]*ActualCostResult
+	}
+
+	var targets []*target
+	var refs []resourceRef
+	direct := map[resourceRef][]*ResourceActualCost{}
+	var directRefs []resourceRef
+	for _, id := range q.ResourceIDs {
+		res := &ResourceActualCost{ResourceID: id}
+		out.Resources = append(out.Resources, res)
+		ref, err := parseResourceRef(id)
+		switch {
+		case err != nil:
+			res.Error = err.Error()
+		case costedDirectly(ref.kind):
+			if _, ok := direct[ref]; !ok {
+				directRefs = append(directRefs, ref)
+			}
+			direct[ref] = append(direct[ref], res)
+		default:
+			refs = append(refs, ref)
+			targets = append(targets, &target{ref: ref, result: res, byTime: map[string]*ActualCostResult{}})
+		}
+	}
+	if len(targets) == 0 && len(direct) == 0 {
+		return out, nil
+	}
+
+	cli := s.client()
+	window := windowFor(cli, q.Start, q.End)
+	ctx = annotateRequest(ctx, "resources", len(refs)+len(directRefs), "window", window)
+
+	if len(directRefs) > 0 {
+		costs, err := directCosts(ctx, cli, window, directRefs)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		for ref, results := range direct {
+			for _, res := range results {
+				res.Results = costs[ref]
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return out, nil
+	}
+
+	err := cli.StreamAllocationEntries(ctx, kubecost.AllocationQuery{
+		Window:      window,
+		Namespaces:  batchNamespaces(refs),
+		AggregateBy: batchAggregation(refs),
+	}, func(_ int, _ string, entry kubecost.AllocationEntry) error {
+		point := entry.ToPoint()
+		for _, t := range targets {
+			if !t.ref.matches(entry.Properties) {
+				continue
+			}
+			if acr, ok := t.byTime[point.Start]; ok {
+				acr.Cost += point.Cost
+				continue
+			}
+			acr := toActualCostResult(point)
+			t.byTime[point.Start] = acr
+			t.result.Results = append(t.result.Results, acr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,146 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+func TestParseResourceRef(t *testing.T) {
+	valid := map[string]resourceRef{
+		"namespace/default":             {kind: "namespace", namespace: "default"},
+		"pod/default/web-1":             {kind: "pod", namespace: "default", name: "web-1"},
+		"controller/prod/api":           {kind: "controller", namespace: "prod", name: "api"},
+		"node/ip-10-0-0-1":              {kind: "node", name: "ip-10-0-0-1"},
+		"pvc/db/data":                   {kind: "pvc", namespace: "db", name: "data"},
+		"cloud/projects/p/instances/db": {kind: "cloud", name: "projects/p/instances/db"},
+		"cloud-label/stack=shop":        {kind: "cloud-label", namespace: "stack", name: "shop"},
+	}
+	for id, want := range valid {
+		got, err := parseResourceRef(id)
+		if err != nil {
+			t.Errorf("parseResourceRef(%s) failed: %v", id, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseResourceRef(%s): expected %+v, got %+v", id, want, got)
+		}
+	}
+
+	for _, id := range []string{"", "namespace", "pod/default", "cluster/x", "node/a/b", "cloud/", "cloud-label/stack"} {
+		if _, err := parseResourceRef(id); err == nil {
+			t.Errorf("Expected error for %q", id)
+		}
+	}
+}
+
+func TestBatchAggregation(t *testing.T) {
+	testCases := []struct {
+		ids  []string
+		want string
+	}{
+		{[]string{"namespace/a", "namespace/b"}, "namespace"},
+		{[]string{"namespace/a", "controller/a/web"}, "namespace,controller"},
+		{[]string{"pod/a/web-1", "controller/a/web"}, "namespace,controller,pod"},
+	}
+	for _, tc := range testCases {
+		var refs []resourceRef
+		for _, id := range tc.ids {
+			ref, _ := parseResourceRef(id)
+			refs = append(refs, ref)
+		}
+		if got := strings.Join(batchAggregation(refs), ","); got != tc.want {
+			t.Errorf("batchAggregation(%v): expected %s, got %s", tc.ids, tc.want, got)
+		}
+	}
+}
+
+func TestBatchActualCost(t *testing.T) {
+	var calls int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if got := r.URL.Query().Get("aggregate"); got != "namespace,controller,pod" {
+			t.Errorf("Expected aggregate=namespace,controller,pod, got %s", got)
+		}
+		if got := r.URL.Query().Get("filter"); got != `namespace:"default","missing"` {
+			t.Errorf("Expected a filter on the batch's namespaces, got %s", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"code": 200,
+			"data": [
+				{
+					"default/web/web-1": {"start": "2024-01-01T00:00:00Z", "totalCost": 1,
+						"properties": {"namespace": "default", "controller": "web", "pod": "web-1"}},
+					"default/web/web-2": {"start": "2024-01-01T00:00:00Z", "totalCost": 2,
+						"properties": {"namespace": "default", "controller": "web", "pod": "web-2"}},
+					"prod/api/api-1": {"start": "2024-01-01T00:00:00Z", "totalCost": 4,
+						"properties": {"namespace": "prod", "controller": "api", "pod": "api-1"}}
+				},
+				{
+					"default/web/web-1": {"start": "2024-01-02T00:00:00Z", "totalCost": 8,
+						"properties": {"namespace": "default", "controller": "web", "pod": "web-1"}}
+				}
+			]
+		}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mockServer.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server := NewKubecostServer(client)
+
+	resp, err := server.BatchActualCost(context.Background(), &BatchActualCostQuery{
+		ResourceIDs: []string{
+			"namespace/default",
+			"controller/default/web",
+			"pod/default/web-2",
+			"namespace/missing",
+			"bogus",
+		},
+		Start: "2024-01-01T00:00:00Z",
+		End:   "2024-01-03T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("BatchActualCost failed: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected a single Kubecost request, got %d", calls)
+	}
+	if len(resp.Resources) != 5 {
+		t.Fatalf("Expected 5 resource results, got %d", len(resp.Resources))
+	}
+
+	costs := func(r *ResourceActualCost) []float64 {
+		var out []float64
+		for _, res := range r.Results {
+			out = append(out, res.Cost)
+		}
+		return out
+	}
+
+	ns := resp.Resources[0]
+	if got := costs(ns); len(got) != 2 || got[0] != 3 || got[1] != 8 {
+		t.Errorf("namespace/default: expected daily costs [3 8], got %v", got)
+	}
+	if got := costs(resp.Resources[1]); len(got) != 2 || got[0] != 3 {
+		t.Errorf("controller/default/web: expected daily costs [3 8], got %v", got)
+	}
+	if got := costs(resp.Resources[2]); len(got) != 1 || got[0] != 2 {
+		t.Errorf("pod/default/web-2: expected [2], got %v", got)
+	}
+	if len(resp.Resources[3].Results) != 0 || resp.Resources[3].Error != "" {
+		t.Errorf("namespace/missing: expected empty results without error, got %+v", resp.Resources[3])
+	}
+	if resp.Resources[4].Error == "" {
+		t.Error("bogus: expected per-resource error")
+	}
+}
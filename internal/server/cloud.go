@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+// cloudCostSource marks results taken from Kubecost cloud costs.
+const cloudCostSource = "kubecost-cloudcost"
+
+// cloudCosts returns the per-window amortized net costs of cloud resources
+// from Kubecost's cloud cost API: one query for all resources named by
+// provider ID and one per label, whose matching resources are summed.
+func cloudCosts(
+	ctx context.Context,
+	cli *kubecost.Client,
+	window string,
+	refs []resourceRef,
+) (map[resourceRef][]*ActualCostResult, error) {
+	out := make(map[resourceRef][]*ActualCostResult, len(refs))
+	var ids []string
+	for _, r := range refs {
+		if r.kind == dimCloud {
+			ids = append(ids, r.name)
+		}
+	}
+	if len(ids) > 0 {
+		resp, err := cli.CloudCost(ctx, kubecost.CloudCostQuery{
+			Window:      window,
+			ProviderIDs: ids,
+			AggregateBy: []string{"providerID"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		addCloudCosts(out, resp, func(cc kubecost.CloudCost) resourceRef {
+			return resourceRef{kind: dimCloud, name: cc.Properties.ProviderID}
+		})
+	}
+
+	for _, r := range refs {
+		if r.kind != dimCloudLabel {
+			continue
+		}
+		resp, err := cli.CloudCost(ctx, kubecost.CloudCostQuery{
+			Window: window,
+			Labels: map[string]string{r.namespace: r.name},
+		})
+		if err != nil {
+			return nil, err
+		}
+		addCloudCosts(out, resp, func(kubecost.CloudCost) resourceRef { return r })
+	}
+	return out, nil
+}
+
+// addCloudCosts adds the costs in resp to out under the resource refOf maps
+// each to, with one result per set and resource.
+func addCloudCosts(
+	out map[resourceRef][]*ActualCostResult,
+	resp *kubecost.CloudCostResponse,
+	refOf func(kubecost.CloudCost) resourceRef,
+) {
+	for _, set := range resp.Data.Sets {
+		bySet := map[resourceRef]*ActualCostResult{}
+		for _, cc := range set.CloudCosts {
+			ref := refOf(cc)
+			if r, ok := bySet[ref]; ok {
+				r.Cost += cc.AmortizedNetCost.Cost
+				continue
+			}
+			start := set.Window.Start
+			if start == "" {
+				start = cc.Window.Start
+			}
+			r := toCostResult(start, cc.AmortizedNetCost.Cost, 0, "", cloudCostSource)
+			bySet[ref] = r
+			out[ref] = append(out[ref], r)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+const (
+	rdsARN = "arn:aws:rds:us-east-1:123:db:orders"
+	s3ARN  = "arn:aws:s3:::shop-assets"
+)
+
+// cloudCostResponse returns a cloud cost response with two daily sets holding the
+// given per-day costs, keyed by provider ID.
+func cloudCostResponse(costs map[string]float64) string {
+	items := ""
+	for id, cost := range costs {
+		if items != "" {
+			items += ","
+		}
+		items += `"` + id + `": {"properties": {"providerID": "` + id + `"},
+			"amortizedNetCost": {"cost": ` + strconv.FormatFloat(cost, 'f', -1, 64) + `}, "listCost": {"cost": 100}}`
+	}
+	return `{"code": 200, "data": {"sets": [
+		{"cloudCosts": {` + items + `}, "window": {"start": "2024-01-01T00:00:00Z"}},
+		{"cloudCosts": {` + items + `}, "window": {"start": "2024-01-02T00:00:00Z"}}
+	]}}`
+}
+
+func newCloudTestServer(t *testing.T, queries *[]url.Values) *KubecostServer {
+	t.Helper()
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/model/cloudCost" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		*queries = append(*queries, r.URL.Query())
+		if r.URL.Query().Get("aggregate") == "providerID" {
+			w.Write([]byte(cloudCostResponse(map[string]float64{rdsARN: 9.5, s3ARN: 0.5})))
+			return
+		}
+		// Resources tagged stack=shop, not aggregated
+		w.Write([]byte(cloudCostResponse(map[string]float64{rdsARN: 9.5, s3ARN: 0.5, "lb-1": 2})))
+	}))
+	t.Cleanup(mock.Close)
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mock.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return NewKubecostServer(client)
+}
+
+func TestGetActualCostCloudResource(t *testing.T) {
+	var queries []url.Values
+	server := newCloudTestServer(t, &queries)
+
+	resp, err := server.GetActualCost(context.Background(), &ActualCostQuery{ResourceID: "cloud/" + rdsARN})
+	if err != nil {
+		t.Fatalf("GetActualCost failed: %v", err)
+	}
+	if len(queries) != 1 || queries[0].Get("filter") != `providerID:"`+rdsARN+`"` {
+		t.Errorf("Expected a single provider ID query, got %v", queries)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("Expected two daily results, got %d", len(resp.Results))
+	}
+	for _, r := range resp.Results {
+		if r.Cost != 9.5 || r.Source != cloudCostSource {
+			t.Errorf("Expected the amortized net cost, got %+v", r)
+		}
+	}
+	if got := resp.Results[1].Timestamp.AsTime().Day(); got != 2 {
+		t.Errorf("Expected the second result on day 2, got %d", got)
+	}
+}
+
+func TestBatchActualCostCloudResources(t *testing.T) {
+	var queries []url.Values
+	server := newCloudTestServer(t, &queries)
+
+	resp, err := server.BatchActualCost(context.Background(), &BatchActualCostQuery{
+		ResourceIDs: []string{"cloud/" + rdsARN, "cloud-label/stack=shop", "cloud/" + s3ARN},
+	})
+	if err != nil {
+		t.Fatalf("BatchActualCost failed: %v", err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("Expected one provider ID and one label query, got %v", queries)
+	}
+	if got := queries[0].Get("filter"); got != `providerID:"`+rdsARN+`","`+s3ARN+`"` {
+		t.Errorf("Unexpected provider ID filter %s", got)
+	}
+	if got := queries[1].Get("filter"); got != `label[stack]:"shop"` {
+		t.Errorf("Unexpected label filter %s", got)
+	}
+
+	want := []float64{9.5, 12, 0.5}
+	for i, cost := range want {
+		res := resp.Resources[i]
+		if len(res.Results) != 2 || res.Results[0].Cost != cost {
+			t.Errorf("%s: expected %v a day, got %+v", res.ResourceID, cost, res.Results)
+		}
+	}
+}
+
+func TestDescriptorResourceIDCloud(t *testing.T) {
+	r := &ResourceDescriptor{ResourceType: "cloud-resource", Tags: map[string]string{"provider_id": rdsARN}}
+	if got := descriptorResourceID(r); got != "cloud/"+rdsARN {
+		t.Errorf("Expected cloud/%s, got %s", rdsARN, got)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain is the ErrorInfo domain attached to Kubecost failures.
+const errorDomain = "kubecost.pulumicost.dev"
+
+// toStatus converts an error from the Kubecost client into a gRPC status error
+// with a code the host can act on. Errors that already carry a gRPC status are
+// returned unchanged.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var apiErr *kubecost.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		// Checked first: a transport error caused by a deadline is still an APIError
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+
+	code := codeForKind(apiErr.Kind)
+	switch {
+	case errors.Is(apiErr.Err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(apiErr.Err, context.Canceled):
+		code = codes.Canceled
+	}
+
+	st := status.New(code, err.Error())
+	details := []protoadapt.MessageV1{errorInfo(apiErr)}
+	if apiErr.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(apiErr.RetryAfter)})
+	}
+	if detailed, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// codeForKind maps a Kubecost error kind to a gRPC status code.
+func codeForKind(kind kubecost.ErrorKind) codes.Code {
+	switch kind {
+	case kubecost.KindUnauthorized:
+		return codes.Unauthenticated
+	case kubecost.KindForbidden:
+		return codes.PermissionDenied
+	case kubecost.KindNotFound:
+		return codes.NotFound
+	case kubecost.KindRateLimited:
+		return codes.ResourceExhausted
+	case kubecost.KindUnavailable:
+		return codes.Unavailable
+	case kubecost.KindBadQuery:
+		return codes.InvalidArgument
+	case kubecost.KindDecode:
+		return codes.Internal
+	case kubecost.KindUnknown:
+	}
+	return codes.Unknown
+}
+
+// errorInfo builds the structured ErrorInfo detail for an APIError.
+func errorInfo(apiErr *kubecost.APIError) *errdetails.ErrorInfo {
+	metadata := map[string]string{}
+	if apiErr.Endpoint != "" {
+		metadata["endpoint"] = apiErr.Endpoint
+	}
+	if apiErr.StatusCode != 0 {
+		metadata["httpStatus"] = strconv.Itoa(apiErr.StatusCode)
+	}
+	if apiErr.Code != 0 {
+		metadata["kubecostCode"] = strconv.Itoa(apiErr.Code)
+	}
+	return &errdetails.ErrorInfo{
+		Reason:   "KUBECOST_" + strings.ToUpper(strings.ReplaceAll(apiErr.Kind.String(), " ", "_")),
+		Domain:   errorDomain,
+		Metadata: metadata,
+	}
+}
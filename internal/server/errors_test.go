@@ -0,0 +1,122 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatusCodes(t *testing.T) {
+	testCases := []struct {
+		err  error
+		want codes.Code
+	}{
+		{&kubecost.APIError{Kind: kubecost.KindUnauthorized}, codes.Unauthenticated},
+		{&kubecost.APIError{Kind: kubecost.KindForbidden}, codes.PermissionDenied},
+		{&kubecost.APIError{Kind: kubecost.KindNotFound}, codes.NotFound},
+		{&kubecost.APIError{Kind: kubecost.KindRateLimited}, codes.ResourceExhausted},
+		{&kubecost.APIError{Kind: kubecost.KindUnavailable}, codes.Unavailable},
+		{&kubecost.APIError{Kind: kubecost.KindBadQuery}, codes.InvalidArgument},
+		{&kubecost.APIError{Kind: kubecost.KindDecode}, codes.Internal},
+		{&kubecost.APIError{Kind: kubecost.KindUnavailable, Err: context.DeadlineExceeded}, codes.DeadlineExceeded},
+		{fmt.Errorf("prediction failed: %w", &kubecost.APIError{Kind: kubecost.KindBadQuery}), codes.InvalidArgument},
+		{context.Canceled, codes.Canceled},
+		{errors.New("boom"), codes.Unknown},
+		{status.Error(codes.Aborted, "already a status"), codes.Aborted},
+	}
+
+	for _, tc := range testCases {
+		if got := status.Code(toStatus(tc.err)); got != tc.want {
+			t.Errorf("toStatus(%v): expected %v, got %v", tc.err, tc.want, got)
+		}
+	}
+
+	if toStatus(nil) != nil {
+		t.Error("Expected nil for nil error")
+	}
+}
+
+func TestToStatusDetails(t *testing.T) {
+	err := toStatus(&kubecost.APIError{
+		Kind:       kubecost.KindRateLimited,
+		Endpoint:   "/model/allocation",
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: 5 * time.Second,
+	})
+
+	st := status.Convert(err)
+	var info *errdetails.ErrorInfo
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		switch v := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = v
+		case *errdetails.RetryInfo:
+			retry = v
+		}
+	}
+
+	if info == nil {
+		t.Fatal("Expected ErrorInfo detail")
+	}
+	if info.GetReason() != "KUBECOST_RATE_LIMITED" {
+		t.Errorf("Expected reason KUBECOST_RATE_LIMITED, got %s", info.GetReason())
+	}
+	if info.GetMetadata()["httpStatus"] != "429" || info.GetMetadata()["endpoint"] != "/model/allocation" {
+		t.Errorf("Unexpected metadata: %v", info.GetMetadata())
+	}
+	if retry == nil || retry.GetRetryDelay().AsDuration() != 5*time.Second {
+		t.Errorf("Expected RetryInfo of 5s, got %v", retry)
+	}
+}
+
+func TestGetActualCostReturnsStatus(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer mockServer.Close()
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mockServer.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server := NewKubecostServer(client)
+
+	_, err = server.GetActualCost(context.Background(), &ActualCostQuery{ResourceID: "namespace/default"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied, got %v", err)
+	}
+}
+
+func TestGetActualCostRateLimitDeadline(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"code": 200, "data": []}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mockServer.URL, RateLimit: 0.1})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server := NewKubecostServer(client)
+	query := &ActualCostQuery{ResourceID: "namespace/default"}
+	if _, err = server.GetActualCost(context.Background(), query); err != nil {
+		t.Fatalf("First GetActualCost failed: %v", err)
+	}
+
+	// The next token is ten seconds away, past the RPC's deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err = server.GetActualCost(ctx, query); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
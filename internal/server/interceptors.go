@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"path"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/metrics"
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/tracing"
+)
+
+// InterceptorOptions configures the interceptors installed by ServerOptions.
+type InterceptorOptions struct {
+	// Timeout is the deadline given to unary RPCs the caller sent without
+	// one; 0 leaves them unbounded.
+	Timeout time.Duration
+	// StreamTimeout is the same for streaming RPCs.
+	StreamTimeout time.Duration
+	// AuthToken, when set, must be sent by callers as
+	// "authorization: Bearer <token>" metadata.
+	AuthToken string
+}
+
+// ServerOptions returns the grpc.Server options installing the plugin's unary
+// and stream interceptor chains. For every RPC they, outermost first:
+//   - start the RPC's server span and scope the logger to the method,
+//   - log the outcome and record it in the RPC metrics,
+//   - reject callers without the auth token, when one is configured,
+//   - apply the default deadline when the caller set none, and
+//   - turn a panic in the handler into an Internal error instead of a crash.
+func ServerOptions(opts InterceptorOptions) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			observeUnary,
+			authUnary(opts.AuthToken),
+			deadlineUnary(opts.Timeout),
+			recoverUnary,
+		),
+		grpc.ChainStreamInterceptor(
+			observeStream,
+			authStream(opts.AuthToken),
+			deadlineStream(opts.StreamTimeout),
+			recoverStream,
+		),
+	}
+}
+
+// methodName returns the method part of a full gRPC method name such as
+// "/pulumicost.v1.CostSource/GetActualCost".
+func methodName(fullMethod string) string {
+	return path.Base(fullMethod)
+}
+
+func observeUnary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	ctx, done := observeRPC(ctx, methodName(info.FullMethod))
+	defer func() { done(err) }()
+	log := logging.FromContext(ctx)
+	log.DebugContext(ctx, "rpc request", "request", req)
+	resp, err = handler(ctx, req)
+	if err == nil {
+		log.DebugContext(ctx, "rpc response", "response", resp)
+	}
+	return resp, err
+}
+
+func observeStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, done := observeRPC(ss.Context(), methodName(info.FullMethod))
+	defer func() { done(err) }()
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// observeRPC starts the RPC's server span and logging scope. The returned
+// function ends the span, logs the RPC's outcome with the time spent waiting
+// on Kubecost and records it in the RPC metrics.
+func observeRPC(ctx context.Context, method string) (context.Context, func(error)) {
+	ctx, span := tracing.StartRPC(ctx, method)
+	ctx = logging.With(ctx, "rpc", method)
+	start := time.Now()
+	return ctx, func(err error) {
+		elapsed := time.Since(start)
+		code := status.Code(err)
+		tracing.End(span, err)
+		metrics.ObserveRPC(method, code.String(), elapsed)
+		log := logging.FromContext(ctx)
+		attrs := []any{"code", code.String(), "duration", elapsed, "kubecost_latency", logging.UpstreamLatency(ctx)}
+		if err != nil {
+			log.WarnContext(ctx, "rpc failed", append(attrs, "error", err)...)
+			return
+		}
+		log.InfoContext(ctx, "rpc completed", attrs...)
+	}
+}
+
+func authUnary(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, token); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func authStream(token string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), token); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorize checks the bearer token in ctx's incoming metadata against token,
+// allowing every caller when token is empty.
+func authorize(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		got, ok := strings.CutPrefix(v, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid authorization token")
+}
+
+func deadlineUnary(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := withDefaultDeadline(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+func deadlineStream(timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := withDefaultDeadline(ss.Context(), timeout)
+		defer cancel()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withDefaultDeadline bounds ctx by timeout unless it already has a deadline
+// or timeout is 0.
+func withDefaultDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func recoverUnary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (_ any, err error) {
+	defer recoverPanic(ctx, info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recoverPanic(ss.Context(), info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+// recoverPanic, deferred by a handler, logs a panic with its stack and reports
+// it to the caller as an Internal error through err. The panic value is not
+// sent to the caller, as it may carry request data.
+func recoverPanic(ctx context.Context, fullMethod string, err *error) {
+	if p := recover(); p != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "rpc panicked", "panic", p, "stack", string(debug.Stack()))
+		*err = status.Errorf(codes.Internal, "internal error in %s", methodName(fullMethod))
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context //nolint:containedctx // the stream's context, replaced
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,175 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const supportsMethod = "/pulumicost.v1.CostSource/Supports"
+
+func TestRecoverUnaryReturnsInternal(t *testing.T) {
+	s := &KubecostServer{}
+	info := &grpc.UnaryServerInfo{FullMethod: supportsMethod}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return s.Supports(ctx, req.(*ResourceDescriptor))
+	}
+
+	// A nil descriptor panics in Supports
+	resp, err := recoverUnary(context.Background(), (*ResourceDescriptor)(nil), info, handler)
+	if resp != nil {
+		t.Errorf("Expected no response, got %v", resp)
+	}
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Supports") {
+		t.Errorf("Expected the method in the error, got %v", err)
+	}
+}
+
+func TestRecoverStreamReturnsInternal(t *testing.T) {
+	stream := &fakeActualCostStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/pulumicost.v1.CostSource/StreamActualCost"}
+	err := recoverStream(nil, stream, info, func(any, grpc.ServerStream) error {
+		panic("mapping failed")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal, got %v", err)
+	}
+}
+
+func TestDeadlineUnary(t *testing.T) {
+	var got time.Time
+	var hasDeadline bool
+	handler := func(ctx context.Context, _ any) (any, error) {
+		got, hasDeadline = ctx.Deadline()
+		return nil, nil //nolint:nilnil // handler result unused
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: supportsMethod}
+
+	before := time.Now()
+	if _, err := deadlineUnary(time.Minute)(context.Background(), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if !hasDeadline || got.Before(before.Add(time.Minute)) || got.After(time.Now().Add(time.Minute)) {
+		t.Errorf("Expected a default deadline a minute out, got %v (set %v)", got, hasDeadline)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if _, err := deadlineUnary(time.Minute)(ctx, nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("Expected the caller's deadline %v to be kept, got %v", want, got)
+	}
+
+	if _, err := deadlineUnary(0)(context.Background(), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if hasDeadline {
+		t.Errorf("Expected no deadline when disabled, got %v", got)
+	}
+}
+
+func TestDeadlineStreamReplacesContext(t *testing.T) {
+	stream := &fakeActualCostStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/pulumicost.v1.CostSource/StreamActualCost"}
+	err := deadlineStream(time.Minute)(nil, stream, info, func(_ any, ss grpc.ServerStream) error {
+		if _, ok := ss.Context().Deadline(); !ok {
+			t.Error("Expected the stream context to carry the default deadline")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObserveUnaryLogsOutcome(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pulumicost.v1.CostSource/GetActualCost"}
+	_, err := observeUnary(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.Unavailable, "kubecost down")
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("Expected the handler's error, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"rpc failed", "rpc=GetActualCost", "code=Unavailable", "kubecost down"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in log, got %s", want, out)
+		}
+	}
+
+	buf.Reset()
+	_, err = observeUnary(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return &ActualCostResultList{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "rpc completed") || !strings.Contains(buf.String(), "code=OK") {
+		t.Errorf("Expected a completion record, got %s", buf.String())
+	}
+}
+
+func TestServerOptionsChainsInterceptors(t *testing.T) {
+	opts := ServerOptions(InterceptorOptions{Timeout: time.Minute, StreamTimeout: time.Hour})
+	if len(opts) != 2 {
+		t.Fatalf("Expected unary and stream chains, got %d options", len(opts))
+	}
+	grpc.NewServer(opts...).Stop()
+}
+
+func TestAuthUnary(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: supportsMethod}
+	handler := func(context.Context, any) (any, error) { return &SupportsResponse{}, nil }
+	call := func(token string, md ...string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(md...))
+		_, err := authUnary(token)(ctx, nil, info, handler)
+		return err
+	}
+
+	if err := call(""); err != nil {
+		t.Errorf("Expected every caller to be allowed without a token, got %v", err)
+	}
+	if err := call("s3cret-token", "authorization", "Bearer s3cret-token"); err != nil {
+		t.Errorf("Expected the configured token to be accepted, got %v", err)
+	}
+	for _, md := range [][]string{
+		nil,
+		{"authorization", "Bearer wrong"},
+		{"authorization", "s3cret-token"},
+	} {
+		if err := call("s3cret-token", md...); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated for metadata %v, got %v", md, err)
+		}
+	}
+}
+
+func TestAuthStream(t *testing.T) {
+	stream := &fakeActualCostStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/pulumicost.v1.CostSource/StreamActualCost"}
+	err := authStream("s3cret-token")(nil, stream, info, func(any, grpc.ServerStream) error {
+		t.Error("Expected the handler not to run")
+		return nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated, got %v", err)
+	}
+}
@@ -3,12 +3,19 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
 )
 
 const (
@@ -16,14 +23,34 @@ const (
 	minPodParts          = 3
 	minControllerParts   = 3
 	minNodeParts         = 2
+	minPVCParts          = 3
 	avgDaysForProjection = 30.0
 )
 
+// failedWindowsTrailer is the trailer listing the chunk windows missing from a
+// partial result, see reportFailedWindows.
+const failedWindowsTrailer = "kubecost-failed-windows"
+
+// Name is the name the plugin reports to the Pulumicost host.
+const Name = "kubecost"
+
+// supportedResources are the resource types Supports accepts; the plugin
+// manifest is generated from this list.
+var supportedResources = []string{"k8s-namespace", "k8s-pod", "k8s-controller", "k8s-node", "k8s-pvc", "cloud-resource"}
+
+// SupportedResources returns the resource types the plugin can cost.
+func SupportedResources() []string {
+	return slices.Clone(supportedResources)
+}
+
 // TODO: Replace these stubs when pulumicost-spec protobuf definitions are available
 type UnimplementedCostSourceServer struct{}
 type Empty struct{}
 type PluginName struct{ Name string }
-type ResourceDescriptor struct{ ResourceType string }
+type ResourceDescriptor struct {
+	Provider, ResourceType, Sku, Region string
+	Tags                                map[string]string
+}
 type SupportsResponse struct{ Supported bool }
 type ActualCostQuery struct{ ResourceID, Start, End string }
 type ActualCostResult struct {
@@ -33,6 +60,7 @@ type ActualCostResult struct {
 	UsageUnit, Source string
 }
 type ActualCostResultList struct{ Results []*ActualCostResult }
+type ActualCostStream = grpc.ServerStreamingServer[ActualCostResult]
 type PriceInfo struct {
 	UnitPrice, CostPerMonth float64
 	Currency, BillingDetail string
@@ -50,11 +78,26 @@ type PredictionResponse struct{ CostBefore, CostAfter, CostChange string }
 
 type KubecostServer struct {
 	UnimplementedCostSourceServer
-	cli *kubecost.Client
+	cli atomic.Pointer[kubecost.Client]
 }
 
 func NewKubecostServer(cli *kubecost.Client) *KubecostServer {
-	return &KubecostServer{cli: cli}
+	s := &KubecostServer{}
+	s.cli.Store(cli)
+	return s
+}
+
+// client returns the current Kubecost client. RPCs load it once so a reload
+// in the middle of a call does not mix two configurations.
+func (s *KubecostServer) client() *kubecost.Client {
+	return s.cli.Load()
+}
+
+// SetClient atomically replaces the Kubecost client used by new RPCs and
+// returns the previous one. Calls already running keep the client they started
+// with.
+func (s *KubecostServer) SetClient(cli *kubecost.Client) *kubecost.Client {
+	return s.cli.Swap(cli)
 }
 
 func (s *KubecostServer) RegisterService(_ *grpc.Server) {
@@ -63,20 +106,98 @@ func (s *KubecostServer) RegisterService(_ *grpc.Server) {
 }
 
 func (s *KubecostServer) Name(_ context.Context, _ *Empty) (*PluginName, error) {
-	return &PluginName{Name: "kubecost"}, nil
+	return &PluginName{Name: Name}, nil
 }
 
 func (s *KubecostServer) Supports(_ context.Context, r *ResourceDescriptor) (*SupportsResponse, error) {
-	supported := r.ResourceType == "k8s-namespace" || r.ResourceType == "k8s-pod" ||
-		r.ResourceType == "k8s-controller" || r.ResourceType == "k8s-node"
-	return &SupportsResponse{Supported: supported}, nil
+	return &SupportsResponse{Supported: slices.Contains(supportedResources, r.ResourceType)}, nil
 }
 
+// GetActualCost returns the actual cost of a resource over the query's window.
+// Nodes and PVCs are costed from their Kubecost asset records, cloud resources
+// from Kubecost cloud costs and other resources from allocations. Chunks left
+// out under the partial chunk failure policy are reported by
+// reportFailedWindows.
 func (s *KubecostServer) GetActualCost(ctx context.Context, q *ActualCostQuery) (*ActualCostResultList, error) {
-	// Map ResourceID like "namespace/default" -> Kubecost filter
-	window := windowFromTimes(q.Start, q.End)
+	cli := s.client()
+	window := windowFor(cli, q.Start, q.End)
+	ctx = annotateRequest(ctx, "resource_id", q.ResourceID, "window", window)
+
+	if ref, ok := directRef(q.ResourceID); ok {
+		costs, err := directCosts(ctx, cli, window, []resourceRef{ref})
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return &ActualCostResultList{Results: costs[ref]}, nil
+	}
+
+	resp, err := cli.EnhancedAllocation(ctx, kubecost.AllocationQuery{
+		Window: window,
+		Filter: filterFromResourceID(q.ResourceID),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	reportFailedWindows(ctx, resp.FailedWindows)
+
+	out := &ActualCostResultList{}
+	for _, it := range resp.Items {
+		out.Results = append(out.Results, toActualCostResult(it))
+	}
+	return out, nil
+}
+
+// StreamActualCost is the server-streaming variant of GetActualCost. Results are
+// sent as they are decoded from Kubecost, so large hourly or per-pod result sets
+// never have to fit in a single message. Send blocks under gRPC flow control,
+// which in turn stops reading the Kubecost response, and cancelling the stream
+// aborts the in-flight HTTP request.
+func (s *KubecostServer) StreamActualCost(q *ActualCostQuery, stream ActualCostStream) error {
+	cli := s.client()
+	window := windowFor(cli, q.Start, q.End)
+	ctx := annotateRequest(stream.Context(), "resource_id", q.ResourceID, "window", window)
+
+	if ref, ok := directRef(q.ResourceID); ok {
+		// A resource's asset or cloud cost sets are small, one record per window step
+		costs, err := directCosts(ctx, cli, window, []resourceRef{ref})
+		if err != nil {
+			return toStatus(err)
+		}
+		for _, r := range costs[ref] {
+			if err = stream.Send(r); err != nil {
+				return toStatus(err)
+			}
+		}
+		return nil
+	}
+
+	err := cli.StreamAllocationPoints(ctx, kubecost.AllocationQuery{
+		Window: window,
+		Filter: filterFromResourceID(q.ResourceID),
+	}, func(it kubecost.AllocationPoint) error {
+		return stream.Send(toActualCostResult(it))
+	})
+	return toStatus(err)
+}
+
+// reportFailedWindows tells the host which chunk windows are missing from a
+// partial allocation result: they are sent in the kubecost-failed-windows
+// trailer, recorded on the RPC's span and logged as a warning.
+func reportFailedWindows(ctx context.Context, windows []string) {
+	if len(windows) == 0 {
+		return
+	}
+	// SetTrailer fails outside a gRPC call, e.g. when GetProjectedCost is called directly
+	_ = grpc.SetTrailer(ctx, metadata.MD{failedWindowsTrailer: windows})
+	trace.SpanFromContext(ctx).SetAttributes(attribute.StringSlice("failed_windows", windows))
+	logging.FromContext(ctx).WarnContext(ctx, "kubecost allocation result is partial",
+		"failed_windows", strings.Join(windows, " "))
+}
+
+// filterFromResourceID maps a ResourceID like "namespace/default" to a Kubecost filter.
+func filterFromResourceID(resourceID string) map[string]string {
 	filter := map[string]string{}
-	parts := strings.Split(q.ResourceID, "/")
+	parts := strings.Split(resourceID, "/")
 	if len(parts) > 0 {
 		switch parts[0] {
 		case "namespace":
@@ -99,37 +220,67 @@ func (s *KubecostServer) GetActualCost(ctx context.Context, q *ActualCostQuery)
 			}
 		}
 	}
+	return filter
+}
 
-	resp, err := s.cli.EnhancedAllocation(ctx, kubecost.AllocationQuery{
-		Window: window,
-		Filter: filter,
-	})
-	if err != nil {
-		return nil, err
+// descriptorResourceID maps a descriptor to the ResourceID of the resource its
+// "namespace", "pod", "controller", "node", "claim" or "provider_id" tags name, e.g. a k8s-pvc
+// tagged namespace=db and claim=data to "pvc/db/data". It returns "", the whole
+// cluster, when the tags the resource type needs are missing.
+func descriptorResourceID(r *ResourceDescriptor) string {
+	ns := r.Tags["namespace"]
+	var kind, name string
+	switch r.ResourceType {
+	case "k8s-namespace":
+		if ns != "" {
+			return dimNamespace + "/" + ns
+		}
+		return ""
+	case "k8s-node":
+		if node := r.Tags["node"]; node != "" {
+			return dimNode + "/" + node
+		}
+		return ""
+	case "k8s-pod":
+		kind, name = dimPod, r.Tags["pod"]
+	case "k8s-controller":
+		kind, name = dimController, r.Tags["controller"]
+	case "k8s-pvc":
+		kind, name = dimPVC, r.Tags["claim"]
+	case "cloud-resource":
+		if id := r.Tags["provider_id"]; id != "" {
+			return dimCloud + "/" + id
+		}
+		return ""
+	}
+	if kind == "" || ns == "" || name == "" {
+		return ""
 	}
+	return kind + "/" + ns + "/" + name
+}
 
-	out := &ActualCostResultList{}
-	for _, it := range resp.Items {
-		// Map Kubecost point → ActualCostResult
-		start, _ := time.Parse(time.RFC3339, it.Start)
-		acr := &ActualCostResult{
-			Timestamp:   timestamppb.New(start),
-			Cost:        it.Cost,
-			UsageAmount: 0,  // Optional: populate from CPU/RAM hours if needed
-			UsageUnit:   "", // Optional
-			Source:      "kubecost",
-		}
-		out.Results = append(out.Results, acr)
+// toActualCostResult maps a Kubecost point to an ActualCostResult.
+func toActualCostResult(it kubecost.AllocationPoint) *ActualCostResult {
+	start, _ := time.Parse(time.RFC3339, it.Start)
+	return &ActualCostResult{
+		Timestamp:   timestamppb.New(start),
+		Cost:        it.Cost,
+		UsageAmount: 0,  // Optional: populate from CPU/RAM hours if needed
+		UsageUnit:   "", // Optional
+		Source:      "kubecost",
 	}
-	return out, nil
 }
 
-func (s *KubecostServer) GetProjectedCost(ctx context.Context, _ *ResourceDescriptor) (*PriceInfo, error) {
+// GetProjectedCost extrapolates a month's cost from the daily average cost of
+// the resource the descriptor's tags name, see descriptorResourceID. The
+// average is taken over the days Kubecost returned costs for, so days lost to
+// failed chunks are left out rather than counted as free.
+func (s *KubecostServer) GetProjectedCost(ctx context.Context, r *ResourceDescriptor) (*PriceInfo, error) {
 	// For MVP, ask Kubecost indirectly by extrapolating last N days average
 	end := time.Now().UTC()
 	start := end.Add(-30 * 24 * time.Hour)
 	acr, err := s.GetActualCost(ctx, &ActualCostQuery{
-		ResourceID: "", // TODO: map from ResourceDescriptor
+		ResourceID: descriptorResourceID(r),
 		Start:      start.Format(time.RFC3339),
 		End:        end.Format(time.RFC3339),
 	})
@@ -141,10 +292,12 @@ func (s *KubecostServer) GetProjectedCost(ctx context.Context, _ *ResourceDescri
 	}
 
 	var sum float64
+	days := map[string]bool{}
 	for _, p := range acr.Results {
 		sum += p.Cost
+		days[p.Timestamp.AsTime().Format(time.DateOnly)] = true
 	}
-	daily := sum / float64(len(acr.Results))
+	daily := sum / float64(len(days))
 	monthly := daily * avgDaysForProjection
 
 	return &PriceInfo{
@@ -155,7 +308,16 @@ func (s *KubecostServer) GetProjectedCost(ctx context.Context, _ *ResourceDescri
 	}, nil
 }
 
-func (s *KubecostServer) GetPricingSpec(_ context.Context, r *ResourceDescriptor) (*PricingSpec, error) {
+// GetPricingSpec returns the pricing of a resource type. Nodes and storage
+// classes are priced from their Kubecost asset records, see nodePricingSpec and
+// storagePricingSpec.
+func (s *KubecostServer) GetPricingSpec(ctx context.Context, r *ResourceDescriptor) (*PricingSpec, error) {
+	switch r.ResourceType {
+	case "k8s-node":
+		return nodePricingSpec(ctx, s.client(), r)
+	case "k8s-pvc":
+		return storagePricingSpec(ctx, s.client(), r)
+	}
 	// Optional: return a synthetic spec expressing CPU/RAM per-hour costs if available
 	return &PricingSpec{
 		Provider:       "kubernetes",
@@ -172,22 +334,27 @@ func (s *KubecostServer) GetPricingSpec(_ context.Context, r *ResourceDescriptor
 
 // PredictSpecCost predicts the cost impact of deploying a Kubernetes workload specification.
 func (s *KubecostServer) PredictSpecCost(ctx context.Context, req *PredictionRequest) (*PredictionResponse, error) {
+	cli := s.client()
+	cfg := cli.GetConfig()
+
 	// Use configuration defaults if not provided in request
 	clusterID := req.ClusterID
 	if clusterID == "" {
-		clusterID = s.cli.GetConfig().ClusterID
+		clusterID = cfg.ClusterID
 	}
 
 	defaultNamespace := req.DefaultNamespace
 	if defaultNamespace == "" {
-		defaultNamespace = s.cli.GetConfig().DefaultNamespace
+		defaultNamespace = cfg.DefaultNamespace
 	}
 
 	window := req.Window
 	if window == "" {
-		window = s.cli.GetConfig().PredictionWindow
+		window = cfg.PredictionWindow
 	}
 
+	ctx = annotateRequest(ctx, "cluster_id", clusterID, "window", window)
+
 	// Create kubecost prediction request
 	kubecostReq := kubecost.PredictionRequest{
 		ClusterID:        clusterID,
@@ -198,9 +365,9 @@ func (s *KubecostServer) PredictSpecCost(ctx context.Context, req *PredictionReq
 	}
 
 	// Call kubecost client
-	resp, err := s.cli.PredictSpecCost(ctx, kubecostReq)
+	resp, err := cli.PredictSpecCost(ctx, kubecostReq)
 	if err != nil {
-		return nil, fmt.Errorf("prediction failed: %w", err)
+		return nil, toStatus(fmt.Errorf("prediction failed: %w", err))
 	}
 
 	return &PredictionResponse{
@@ -210,6 +377,17 @@ func (s *KubecostServer) PredictSpecCost(ctx context.Context, req *PredictionReq
 	}, nil
 }
 
+// windowFor returns the allocation window for a query, falling back to the
+// configured default window when the request carries no time range.
+func windowFor(cli *kubecost.Client, start, end string) string {
+	if start == "" || end == "" {
+		if w := cli.GetConfig().DefaultWindow; w != "" {
+			return w
+		}
+	}
+	return windowFromTimes(start, end)
+}
+
 func windowFromTimes(start, end string) string {
 	if start == "" || end == "" {
 		return "30d"
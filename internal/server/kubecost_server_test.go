@@ -2,14 +2,17 @@ package server //nolint:testpackage // Package name intentionally matches implem
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -77,7 +80,7 @@ func TestNewKubecostServer(t *testing.T) {
 		t.Fatal("NewKubecostServer should not return nil")
 	}
 
-	if server.cli != mockClient {
+	if server.client() != mockClient {
 		t.Error("Server should have the provided client")
 	}
 }
@@ -239,6 +242,19 @@ func TestWindowFromTimes(t *testing.T) {
 	}
 }
 
+func TestWindowForUsesConfiguredDefault(t *testing.T) {
+	cli, err := kubecost.NewClient(context.Background(), kubecost.Config{DefaultWindow: "7d"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if window := windowFor(cli, "", ""); window != "7d" {
+		t.Errorf("Expected configured default window 7d, got %s", window)
+	}
+	if window := windowFor(cli, "2024-01-01T00:00:00Z", "2024-01-02T00:00:00Z"); window != "2024-01-01T00:00:00Z,2024-01-02T00:00:00Z" {
+		t.Errorf("Expected request time range to override the default, got %s", window)
+	}
+}
+
 func TestTimestamppb(t *testing.T) {
 	now := time.Now().UTC()
 	ts := timestamppb.New(now)
@@ -683,3 +699,188 @@ func TestPredictSpecCostError(t *testing.T) {
 		t.Error("Expected error for invalid workload specification")
 	}
 }
+
+// fakeActualCostStream collects results sent on a StreamActualCost stream.
+type fakeActualCostStream struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	results []*ActualCostResult
+	sendErr error
+}
+
+func (f *fakeActualCostStream) Context() context.Context { return f.ctx }
+
+func (f *fakeActualCostStream) Send(r *ActualCostResult) error {
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.results = append(f.results, r)
+	return nil
+}
+
+func newAllocationTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("filter") != `namespace:"default"` {
+			t.Errorf("Expected namespace filter, got %s", r.URL.Query().Get("filter"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"code": 200,
+			"data": [
+				{"a": {"start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z", "totalCost": 1.25}},
+				{"a": {"start": "2024-01-02T00:00:00Z", "end": "2024-01-03T00:00:00Z", "totalCost": 2.50}}
+			]
+		}`))
+	}))
+}
+
+func TestStreamActualCost(t *testing.T) {
+	mockServer := newAllocationTestServer(t)
+	defer mockServer.Close()
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mockServer.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server := NewKubecostServer(client)
+
+	stream := &fakeActualCostStream{ctx: context.Background()}
+	err = server.StreamActualCost(&ActualCostQuery{
+		ResourceID: "namespace/default",
+		Start:      "2024-01-01T00:00:00Z",
+		End:        "2024-01-03T00:00:00Z",
+	}, stream)
+	if err != nil {
+		t.Fatalf("StreamActualCost failed: %v", err)
+	}
+
+	if len(stream.results) != 2 {
+		t.Fatalf("Expected 2 streamed results, got %d", len(stream.results))
+	}
+	if stream.results[1].Cost != 2.50 {
+		t.Errorf("Expected second result cost 2.50, got %f", stream.results[1].Cost)
+	}
+	if stream.results[0].Source != "kubecost" {
+		t.Errorf("Expected source kubecost, got %s", stream.results[0].Source)
+	}
+}
+
+func TestStreamActualCostSendError(t *testing.T) {
+	mockServer := newAllocationTestServer(t)
+	defer mockServer.Close()
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mockServer.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server := NewKubecostServer(client)
+
+	sendErr := context.Canceled
+	stream := &fakeActualCostStream{ctx: context.Background(), sendErr: sendErr}
+	err = server.StreamActualCost(&ActualCostQuery{ResourceID: "namespace/default"}, stream)
+	if err == nil {
+		t.Fatal("Expected error when the client stops receiving")
+	}
+}
+
+func TestStreamActualCostCancelled(t *testing.T) {
+	mockServer := newAllocationTestServer(t)
+	defer mockServer.Close()
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mockServer.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server := NewKubecostServer(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream := &fakeActualCostStream{ctx: ctx}
+	if err = server.StreamActualCost(&ActualCostQuery{ResourceID: "namespace/default"}, stream); err == nil {
+		t.Fatal("Expected error for cancelled stream")
+	}
+	if len(stream.results) != 0 {
+		t.Errorf("Expected no results after cancellation, got %d", len(stream.results))
+	}
+}
+
+// fakeTransportStream records the trailer set on a unary RPC.
+type fakeTransportStream struct {
+	grpc.ServerTransportStream
+
+	trailer metadata.MD
+}
+
+func (f *fakeTransportStream) SetTrailer(md metadata.MD) error {
+	f.trailer = metadata.Join(f.trailer, md)
+	return nil
+}
+
+// newPartialChunkServer serves two containers a day and fails the chunks whose
+// RFC3339 start fails reports, with a client splitting windows into ten-day
+// chunks under the partial failure policy.
+func newPartialChunkServer(t *testing.T, fails func(start string) bool) *KubecostServer {
+	t.Helper()
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _, _ := strings.Cut(r.URL.Query().Get("window"), ",")
+		if fails(start) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"code": 200, "data": [{
+			"a": {"start": %[1]q, "totalCost": 1},
+			"b": {"start": %[1]q, "totalCost": 3}
+		}]}`, start)
+	}))
+	t.Cleanup(mock.Close)
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{
+		BaseURL:            mock.URL,
+		ChunkWindow:        "10d",
+		ChunkFailurePolicy: kubecost.ChunkPolicyPartial,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return NewKubecostServer(client)
+}
+
+func TestGetActualCostReportsFailedWindows(t *testing.T) {
+	server := newPartialChunkServer(t, func(start string) bool { return start == "2024-01-11T00:00:00Z" })
+
+	stream := &fakeTransportStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	resp, err := server.GetActualCost(ctx, &ActualCostQuery{
+		ResourceID: "namespace/default", Start: "2024-01-01T00:00:00Z", End: "2024-01-31T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("GetActualCost failed: %v", err)
+	}
+	if len(resp.Results) != 4 {
+		t.Errorf("Expected the results of two chunks, got %d", len(resp.Results))
+	}
+	want := []string{"2024-01-11T00:00:00Z,2024-01-21T00:00:00Z"}
+	if got := stream.trailer.Get(failedWindowsTrailer); !slices.Equal(got, want) {
+		t.Errorf("Expected failed windows %v in the trailer, got %v", want, got)
+	}
+}
+
+func TestGetProjectedCostSkipsFailedWindows(t *testing.T) {
+	// The first of the three chunks of the 30-day projection window fails
+	cutoff := time.Now().UTC().Add(-25 * 24 * time.Hour).Format(time.RFC3339)
+	server := newPartialChunkServer(t, func(start string) bool { return start < cutoff })
+
+	info, err := server.GetProjectedCost(context.Background(), &ResourceDescriptor{
+		ResourceType: "k8s-namespace",
+		Tags:         map[string]string{"namespace": "default"},
+	})
+	if err != nil {
+		t.Fatalf("GetProjectedCost failed: %v", err)
+	}
+	if info.UnitPrice != 4 || info.CostPerMonth != 4*avgDaysForProjection {
+		t.Errorf("Expected the daily cost of both containers on the days returned, got %+v", info)
+	}
+}
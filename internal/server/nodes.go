@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// capacityPreference is the order in which node pricing picks a capacity type
+// when the descriptor names none: list prices first.
+var capacityPreference = []string{kubecost.CapacityOnDemand, kubecost.CapacityReserved, kubecost.CapacitySpot}
+
+// nodePricingSpec returns the hourly price of a node from Kubecost's node
+// assets over the default window. The descriptor names either one node, with
+// a "node" tag, or an instance type as its SKU, optionally narrowed by region
+// or zone and by a "capacity_type" tag. Nodes of one instance type are averaged
+// over the capacity type named, or else the first of on-demand, reserved and
+// spot that is running.
+func nodePricingSpec(ctx context.Context, cli *kubecost.Client, r *ResourceDescriptor) (*PricingSpec, error) {
+	name := r.Tags["node"]
+	if name == "" && r.Sku == "" {
+		return nil, status.Error(codes.InvalidArgument, "k8s-node pricing needs a node tag or an instance type SKU")
+	}
+	window := windowFor(cli, "", "")
+	ctx = annotateRequest(ctx, "node", name, "sku", r.Sku, "region", r.Region, "window", window)
+
+	q := kubecost.AssetsQuery{Window: window, Types: []string{kubecost.AssetNode}, Accumulate: true}
+	if name != "" {
+		q.Filter = map[string]string{"name": name}
+	}
+	resp, err := cli.Assets(ctx, q)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	byCapacity := map[string][]kubecost.Asset{}
+	for _, set := range resp.Data {
+		for _, asset := range set {
+			if nodeMatches(asset, name, r) {
+				capacity := asset.CapacityType()
+				byCapacity[capacity] = append(byCapacity[capacity], asset)
+			}
+		}
+	}
+	capacity := r.Tags["capacity_type"]
+	if capacity == "" {
+		for _, c := range capacityPreference {
+			if len(byCapacity[c]) > 0 {
+				capacity = c
+				break
+			}
+		}
+	}
+	nodes := byCapacity[capacity]
+	if len(nodes) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no Kubecost node assets match %s over %s", nodeDescription(name, r), window)
+	}
+	slices.SortFunc(nodes, func(a, b kubecost.Asset) int {
+		return strings.Compare(a.Properties.Cluster+"/"+a.Properties.Name, b.Properties.Cluster+"/"+b.Properties.Name)
+	})
+	return toNodePricingSpec(r.ResourceType, capacity, window, nodes), nil
+}
+
+// nodeMatches reports whether asset is the named node or, without a name, a
+// node of the descriptor's instance type in its region or zone.
+func nodeMatches(asset kubecost.Asset, name string, r *ResourceDescriptor) bool {
+	if asset.Type != kubecost.AssetNode {
+		return false
+	}
+	if name != "" {
+		return asset.Properties.Name == name
+	}
+	if asset.InstanceType() != r.Sku {
+		return false
+	}
+	return r.Region == "" || asset.Region() == r.Region || asset.Zone() == r.Region
+}
+
+func nodeDescription(name string, r *ResourceDescriptor) string {
+	if name != "" {
+		return "node " + name
+	}
+	if r.Region != "" {
+		return fmt.Sprintf("instance type %s in %s", r.Sku, r.Region)
+	}
+	return "instance type " + r.Sku
+}
+
+// toNodePricingSpec prices nodes, which share an instance type and capacity
+// type, at their combined cost over their combined running time. Placement is
+// taken from the first node; the zone is reported only when all nodes share it.
+func toNodePricingSpec(resourceType, capacity, window string, nodes []kubecost.Asset) *PricingSpec {
+	first := nodes[0]
+	var sum kubecost.Asset
+	zone := first.Zone()
+	for _, n := range nodes {
+		sum.TotalCost += n.TotalCost
+		sum.Minutes += n.Minutes
+		sum.CPUCost += n.CPUCost
+		sum.CPUCoreHours += n.CPUCoreHours
+		sum.RAMCost += n.RAMCost
+		sum.RAMByteHours += n.RAMByteHours
+		sum.GPUCost += n.GPUCost
+		sum.GPUHours += n.GPUHours
+		if n.Zone() != zone {
+			zone = ""
+		}
+	}
+
+	metadata := map[string]string{
+		"source":               assetCostSource,
+		"window":               window,
+		"nodes":                strconv.Itoa(len(nodes)),
+		"capacity_type":        capacity,
+		"cpu_core_hourly_rate": formatRate(sum.CPUCoreHourlyRate()),
+		"ram_gib_hourly_rate":  formatRate(sum.RAMGiBHourlyRate()),
+		"gpu_hourly_rate":      formatRate(sum.GPUHourlyRate()),
+	}
+	if zone != "" {
+		metadata["zone"] = zone
+	}
+	if len(nodes) == 1 {
+		metadata["node"] = first.Properties.Name
+		metadata["cluster"] = first.Properties.Cluster
+	}
+
+	return &PricingSpec{
+		Provider:       first.CloudProvider(),
+		ResourceType:   resourceType,
+		Sku:            first.InstanceType(),
+		Region:         first.Region(),
+		BillingMode:    "per_hour",
+		RatePerUnit:    sum.HourlyCost(),
+		Currency:       "USD",
+		Description:    fmt.Sprintf("Kubecost %s node price for %s", capacity, first.InstanceType()),
+		PluginMetadata: metadata,
+	}
+}
+
+func formatRate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
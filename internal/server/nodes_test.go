@@ -0,0 +1,225 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// nodeAssetsResponse holds two daily sets with two nodes, one of them present in
+// two clusters on the first day.
+const nodeAssetsResponse = `{
+	"code": 200,
+	"data": [
+		{
+			"a/n1": {"type": "Node", "properties": {"cluster": "a", "name": "n1"},
+				"start": "2024-01-01T00:00:00Z", "minutes": 1440, "totalCost": 2},
+			"b/n1": {"type": "Node", "properties": {"cluster": "b", "name": "n1"},
+				"start": "2024-01-01T00:00:00Z", "minutes": 720, "totalCost": 1},
+			"a/n2": {"type": "Node", "properties": {"cluster": "a", "name": "n2"},
+				"start": "2024-01-01T00:00:00Z", "minutes": 1440, "totalCost": 5}
+		},
+		{
+			"a/n1": {"type": "Node", "properties": {"cluster": "a", "name": "n1"},
+				"start": "2024-01-02T00:00:00Z", "minutes": 1440, "totalCost": 4}
+		}
+	]
+}`
+
+// newNodeTestServer serves nodeAssetsResponse on the assets API and a single
+// namespace allocation on the allocation API, recording the paths requested.
+func newNodeTestServer(t *testing.T, paths *[]string) *KubecostServer {
+	t.Helper()
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.Path+"?"+r.URL.Query().Get("filter"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/model/assets" {
+			w.Write([]byte(nodeAssetsResponse))
+			return
+		}
+		w.Write([]byte(`{"code": 200, "data": [{"default": {"start": "2024-01-01T00:00:00Z", "totalCost": 7,
+			"properties": {"namespace": "default"}}}]}`))
+	}))
+	t.Cleanup(mock.Close)
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mock.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return NewKubecostServer(client)
+}
+
+func TestGetActualCostNodeFromAssets(t *testing.T) {
+	var paths []string
+	server := newNodeTestServer(t, &paths)
+
+	resp, err := server.GetActualCost(context.Background(), &ActualCostQuery{
+		ResourceID: "node/n1", Start: "2024-01-01T00:00:00Z", End: "2024-01-03T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("GetActualCost failed: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != `/model/assets?assetType:"node"+name:"n1"` {
+		t.Errorf("Expected a single node assets query, got %v", paths)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("Expected two daily results, got %d", len(resp.Results))
+	}
+	first := resp.Results[0]
+	if first.Cost != 3 || first.UsageAmount != 36 || first.UsageUnit != "hours" || first.Source != assetCostSource {
+		t.Errorf("Expected both clusters' n1 summed on day one, got %+v", first)
+	}
+	if resp.Results[1].Cost != 4 {
+		t.Errorf("Expected day two cost 4, got %v", resp.Results[1].Cost)
+	}
+}
+
+func TestStreamActualCostNodeFromAssets(t *testing.T) {
+	var paths []string
+	server := newNodeTestServer(t, &paths)
+
+	stream := &fakeActualCostStream{ctx: context.Background()}
+	if err := server.StreamActualCost(&ActualCostQuery{ResourceID: "node/n2"}, stream); err != nil {
+		t.Fatalf("StreamActualCost failed: %v", err)
+	}
+	if len(stream.results) != 1 || stream.results[0].Cost != 5 {
+		t.Errorf("Expected one n2 result costing 5, got %+v", stream.results)
+	}
+}
+
+func TestBatchActualCostNodesFromAssets(t *testing.T) {
+	var paths []string
+	server := newNodeTestServer(t, &paths)
+
+	resp, err := server.BatchActualCost(context.Background(), &BatchActualCostQuery{
+		ResourceIDs: []string{"node/n1", "namespace/default", "node/n2", "node/missing"},
+	})
+	if err != nil {
+		t.Fatalf("BatchActualCost failed: %v", err)
+	}
+	if len(paths) != 2 || !strings.HasPrefix(paths[0], `/model/assets?assetType:"node"`) ||
+		strings.Contains(paths[0], "name:") || !strings.HasPrefix(paths[1], "/model/allocation") {
+		t.Errorf("Expected one unfiltered node assets query and one allocation query, got %v", paths)
+	}
+
+	total := func(r *ResourceActualCost) float64 {
+		var sum float64
+		for _, res := range r.Results {
+			sum += res.Cost
+		}
+		return sum
+	}
+	want := []float64{7, 7, 5, 0}
+	for i, r := range resp.Resources {
+		if got := total(r); got != want[i] || r.Error != "" {
+			t.Errorf("%s: expected total %v, got %v (error %q)", r.ResourceID, want[i], got, r.Error)
+		}
+	}
+}
+
+// nodePricingResponse holds an accumulated set of three m5.large nodes, one of
+// them spot, and a c5.xlarge node.
+const nodePricingResponse = `{
+	"code": 200,
+	"data": [{
+		"a/n1": {"type": "Node", "nodeType": "m5.large", "minutes": 600, "totalCost": 1,
+			"cpuCost": 0.6, "cpuCoreHours": 20, "ramCost": 0.4, "ramByteHours": 85899345920,
+			"properties": {"cluster": "a", "name": "n1", "providerID": "aws:///us-east-1a/i-1"}},
+		"a/n2": {"type": "Node", "nodeType": "m5.large", "minutes": 600, "totalCost": 3,
+			"cpuCost": 1.8, "cpuCoreHours": 20, "ramCost": 1.2, "ramByteHours": 85899345920,
+			"properties": {"cluster": "a", "name": "n2", "providerID": "aws:///us-east-1b/i-2"}},
+		"a/n3": {"type": "Node", "nodeType": "m5.large", "minutes": 600, "totalCost": 0.5, "preemptible": 1,
+			"properties": {"cluster": "a", "name": "n3", "providerID": "aws:///us-east-1a/i-3"}},
+		"a/n4": {"type": "Node", "nodeType": "c5.xlarge", "minutes": 60, "totalCost": 0.17,
+			"properties": {"cluster": "a", "name": "n4", "providerID": "aws:///eu-west-1a/i-4"}}
+	}]
+}`
+
+func TestGetPricingSpecNode(t *testing.T) {
+	var query string
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(nodePricingResponse))
+	}))
+	defer mock.Close()
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mock.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server := NewKubecostServer(client)
+
+	tests := []struct {
+		name       string
+		desc       *ResourceDescriptor
+		rate       float64
+		capacity   string
+		zone, node string
+	}{
+		{
+			name:     "instance type prefers on-demand",
+			desc:     &ResourceDescriptor{Sku: "m5.large", Region: "us-east-1"},
+			rate:     0.2,
+			capacity: kubecost.CapacityOnDemand,
+		},
+		{
+			name:     "capacity type tag",
+			desc:     &ResourceDescriptor{Sku: "m5.large", Tags: map[string]string{"capacity_type": "spot"}},
+			rate:     0.05,
+			capacity: kubecost.CapacitySpot,
+			zone:     "us-east-1a",
+			node:     "n3",
+		},
+		{
+			name:     "node tag",
+			desc:     &ResourceDescriptor{Tags: map[string]string{"node": "n1"}},
+			rate:     0.1,
+			capacity: kubecost.CapacityOnDemand,
+			zone:     "us-east-1a",
+			node:     "n1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.desc.ResourceType = "k8s-node"
+			spec, err := server.GetPricingSpec(context.Background(), tt.desc)
+			if err != nil {
+				t.Fatalf("GetPricingSpec failed: %v", err)
+			}
+			if spec.Provider != "aws" || spec.Sku != "m5.large" || spec.Region != "us-east-1" ||
+				spec.BillingMode != "per_hour" {
+				t.Errorf("Unexpected spec %+v", spec)
+			}
+			if math.Abs(spec.RatePerUnit-tt.rate) > 1e-9 {
+				t.Errorf("Expected rate %v, got %v", tt.rate, spec.RatePerUnit)
+			}
+			md := spec.PluginMetadata
+			if md["capacity_type"] != tt.capacity || md["zone"] != tt.zone || md["node"] != tt.node {
+				t.Errorf("Unexpected metadata %v", md)
+			}
+		})
+	}
+
+	spec, _ := server.GetPricingSpec(context.Background(), &ResourceDescriptor{ResourceType: "k8s-node", Sku: "m5.large"})
+	if md := spec.PluginMetadata; md["cpu_core_hourly_rate"] != "0.06" || md["ram_gib_hourly_rate"] != "0.01" {
+		t.Errorf("Expected CPU and RAM rates of the on-demand nodes, got %v", md)
+	}
+	if !strings.Contains(query, "accumulate=true") {
+		t.Errorf("Expected an accumulated assets query, got %s", query)
+	}
+
+	for desc, want := range map[*ResourceDescriptor]codes.Code{
+		{ResourceType: "k8s-node"}:                                       codes.InvalidArgument,
+		{ResourceType: "k8s-node", Sku: "m5.large", Region: "eu-west-1"}: codes.NotFound,
+	} {
+		if _, err := server.GetPricingSpec(context.Background(), desc); status.Code(err) != want {
+			t.Errorf("Expected %v for %+v, got %v", want, desc, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+// Reloader rebuilds the Kubecost client when the configuration changes and
+// swaps it into a KubecostServer. An invalid configuration is logged and the
+// current client is kept.
+type Reloader struct {
+	srv      *KubecostServer
+	opts     kubecost.LoadOptions
+	interval time.Duration
+	stamp    fileStamp
+	onReload func(kubecost.Config)
+}
+
+// fileStamp identifies a version of the config file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (f fileStamp) equal(o fileStamp) bool {
+	return f.modTime.Equal(o.modTime) && f.size == o.size
+}
+
+func statConfig(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// NewReloader returns a reloader that loads configuration with opts, the same
+// layers the plugin started with. The config file at opts.Path is polled every
+// interval; zero disables polling so only explicit reloads apply.
+func NewReloader(srv *KubecostServer, opts kubecost.LoadOptions, interval time.Duration) *Reloader {
+	r := &Reloader{srv: srv, opts: opts, interval: interval}
+	if opts.Path != "" {
+		r.stamp, _ = statConfig(opts.Path)
+	}
+	return r
+}
+
+// OnReload registers fn to be called with each configuration that is applied,
+// for settings outside the Kubecost client such as logging.
+func (r *Reloader) OnReload(fn func(kubecost.Config)) {
+	r.onReload = fn
+}
+
+// Reload loads and validates the configuration and, if it is valid, swaps a
+// new client into the server. On error the current client is kept.
+func (r *Reloader) Reload(ctx context.Context) error {
+	cfg, err := kubecost.LoadValidatedConfig(r.opts)
+	if err != nil {
+		return err
+	}
+	cli, err := kubecost.NewClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("building client: %w", err)
+	}
+	if old := r.srv.SetClient(cli); old != nil {
+		old.Close()
+	}
+	if r.onReload != nil {
+		r.onReload(cfg)
+	}
+	return nil
+}
+
+// Run reloads on every signal received from hup and, when polling is enabled,
+// whenever the config file's modification time or size changes. It returns
+// when ctx is done.
+func (r *Reloader) Run(ctx context.Context, hup <-chan os.Signal) {
+	var tick <-chan time.Time
+	if r.interval > 0 && r.opts.Path != "" {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-hup:
+			r.stamp, _ = statConfig(r.opts.Path)
+			r.reload(ctx, sig.String())
+		case <-tick:
+			// A missing file, e.g. mid-way through a ConfigMap update, is
+			// retried on the next tick
+			stamp, err := statConfig(r.opts.Path)
+			if err != nil || stamp.equal(r.stamp) {
+				continue
+			}
+			r.stamp = stamp
+			r.reload(ctx, r.opts.Path+" changed")
+		}
+	}
+}
+
+func (r *Reloader) reload(ctx context.Context, reason string) {
+	if err := r.Reload(ctx); err != nil {
+		slog.ErrorContext(ctx, "config reload failed, keeping current client", "reason", reason, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "config reloaded", "reason", reason)
+}
@@ -0,0 +1,106 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+func writeConfig(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+}
+
+func waitForBaseURL(t *testing.T, srv *KubecostServer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if srv.client().GetConfig().BaseURL == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected base URL %s, still %s", want, srv.client().GetConfig().BaseURL)
+}
+
+func newReloadTestServer(t *testing.T, path string) *KubecostServer {
+	t.Helper()
+	cfg, err := kubecost.LoadValidatedConfig(kubecost.LoadOptions{Path: path})
+	if err != nil {
+		t.Fatalf("loading config: %v", err)
+	}
+	cli, err := kubecost.NewClient(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return NewKubecostServer(cli)
+}
+
+func TestReloaderWatchesConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	start := time.Now().Add(-time.Hour)
+	writeConfig(t, path, "baseUrl: http://kubecost-a:9090\n", start)
+
+	srv := newReloadTestServer(t, path)
+	reloader := NewReloader(srv, kubecost.LoadOptions{Path: path}, 5*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Run(ctx, nil)
+
+	writeConfig(t, path, "baseUrl: http://kubecost-b:9090\n", start.Add(time.Minute))
+	waitForBaseURL(t, srv, "http://kubecost-b:9090")
+
+	// An invalid config keeps the current client
+	previous := srv.client()
+	writeConfig(t, path, "baseUrl: http://kubecost-c:9090\ntimeout: soon\n", start.Add(2*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	if srv.client() != previous {
+		t.Errorf("Expected invalid config to keep the current client, got %s", srv.client().GetConfig().BaseURL)
+	}
+
+	writeConfig(t, path, "baseUrl: http://kubecost-d:9090\n", start.Add(3*time.Minute))
+	waitForBaseURL(t, srv, "http://kubecost-d:9090")
+}
+
+func TestReloaderOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "baseUrl: http://kubecost-a:9090\n", time.Now())
+
+	srv := newReloadTestServer(t, path)
+	reloader := NewReloader(srv, kubecost.LoadOptions{Path: path}, 0)
+	hup := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Run(ctx, hup)
+
+	// Same modification time: only the signal triggers the reload
+	info, _ := os.Stat(path)
+	writeConfig(t, path, "baseUrl: http://kubecost-b:9090\n", info.ModTime())
+	hup <- syscall.SIGHUP
+	waitForBaseURL(t, srv, "http://kubecost-b:9090")
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "baseUrl: http://kubecost-a:9090\n", time.Now())
+	srv := newReloadTestServer(t, path)
+	previous := srv.client()
+
+	writeConfig(t, path, "baseUrl: not a url\nbogus: true\n", time.Now())
+	if err := NewReloader(srv, kubecost.LoadOptions{Path: path}, 0).Reload(context.Background()); err == nil {
+		t.Fatal("Expected Reload to fail for an invalid config")
+	}
+	if srv.client() != previous {
+		t.Error("Expected the previous client to be kept")
+	}
+}
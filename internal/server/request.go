@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
+)
+
+// annotateRequest adds an RPC's arguments, slog-style key-value pairs, to the
+// RPC's server span and to ctx's logger, so Kubecost calls made for it are
+// logged with them. The span and logging scope themselves are started by the
+// interceptors installed with ServerOptions.
+func annotateRequest(ctx context.Context, args ...any) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(spanAttributes(args)...)
+	return logging.With(ctx, args...)
+}
+
+// spanAttributes converts slog-style key-value pairs to span attributes.
+func spanAttributes(args []any) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		key := fmt.Sprint(args[i])
+		switch v := args[i+1].(type) {
+		case int:
+			attrs = append(attrs, attribute.Int(key, v))
+		case string:
+			attrs = append(attrs, attribute.String(key, v))
+		default:
+			attrs = append(attrs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	return attrs
+}
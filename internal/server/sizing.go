@@ -0,0 +1,98 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TODO: Replace these stubs when pulumicost-spec protobuf definitions are available
+type SizingRecommendationQuery struct {
+	ResourceID, Window string
+	TargetUtilization  float64
+}
+type ContainerSizing struct {
+	Cluster, Namespace, ControllerKind, Controller, Container string
+	CurrentCPURequest, CurrentMemoryRequest                   string
+	RecommendedCPURequest, RecommendedMemoryRequest           string
+	MonthlySavings, CPUMonthlySavings, MemoryMonthlySavings   float64
+	CPUEfficiency, MemoryEfficiency                           float64
+}
+type SizingRecommendationList struct {
+	Containers        []*ContainerSizing
+	MonthlySavings    float64
+	TargetUtilization float64
+	Window            string
+}
+
+// GetSizingRecommendations returns Kubecost's request-sizing recommendations
+// for the containers of a controller ("controller/<ns>/<name>") or namespace
+// ("namespace/<ns>"): their current and recommended CPU and memory requests and
+// the monthly savings, largest first. The window and target utilization
+// default to sizingWindow and sizingTargetUtilization; a target of 0 is
+// Kubecost's own default.
+func (s *KubecostServer) GetSizingRecommendations(
+	ctx context.Context,
+	q *SizingRecommendationQuery,
+) (*SizingRecommendationList, error) {
+	ref, err := parseResourceRef(q.ResourceID)
+	if err != nil || (ref.kind != dimNamespace && ref.kind != dimController) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"sizing recommendations need a namespace or controller resource ID, got %q", q.ResourceID)
+	}
+
+	cli := s.client()
+	cfg := cli.GetConfig()
+	window := cmp.Or(q.Window, cfg.SizingWindow)
+	target := cmp.Or(q.TargetUtilization, cfg.SizingTargetUtilization)
+	ctx = annotateRequest(ctx, "resource_id", q.ResourceID, "window", window, "target_utilization", target)
+
+	filter := map[string]string{"namespace": ref.namespace}
+	if ref.kind == dimController {
+		filter["controllerName"] = ref.name
+	}
+	resp, err := cli.RequestSizing(ctx, kubecost.RequestSizingQuery{
+		Window:               window,
+		Filter:               filter,
+		TargetCPUUtilization: target,
+		TargetRAMUtilization: target,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	out := &SizingRecommendationList{TargetUtilization: target, Window: window}
+	for _, rec := range resp.Data.Recommendations {
+		c := toContainerSizing(rec)
+		out.Containers = append(out.Containers, c)
+		out.MonthlySavings += c.MonthlySavings
+	}
+	slices.SortStableFunc(out.Containers, func(a, b *ContainerSizing) int {
+		return cmp.Compare(b.MonthlySavings, a.MonthlySavings)
+	})
+	return out, nil
+}
+
+// toContainerSizing maps a Kubecost recommendation to a ContainerSizing.
+func toContainerSizing(rec kubecost.SizingRecommendation) *ContainerSizing {
+	return &ContainerSizing{
+		Cluster:                  rec.ClusterID,
+		Namespace:                rec.Namespace,
+		ControllerKind:           rec.ControllerKind,
+		Controller:               rec.ControllerName,
+		Container:                rec.ContainerName,
+		CurrentCPURequest:        rec.LatestKnownRequest.CPU,
+		CurrentMemoryRequest:     rec.LatestKnownRequest.Memory,
+		RecommendedCPURequest:    rec.RecommendedRequest.CPU,
+		RecommendedMemoryRequest: rec.RecommendedRequest.Memory,
+		MonthlySavings:           rec.MonthlySavings.Total(),
+		CPUMonthlySavings:        rec.MonthlySavings.CPU,
+		MemoryMonthlySavings:     rec.MonthlySavings.Memory,
+		CPUEfficiency:            rec.CurrentEfficiency.CPU,
+		MemoryEfficiency:         rec.CurrentEfficiency.Memory,
+	}
+}
@@ -0,0 +1,113 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const sizingResponse = `{
+	"code": 200,
+	"data": {
+		"Recommendations": [
+			{"clusterID": "c", "namespace": "shop", "controllerKind": "deployment", "controllerName": "api",
+				"containerName": "sidecar",
+				"recommendedRequest": {"cpu": "10m", "memory": "32Mi"},
+				"latestKnownRequest": {"cpu": "50m", "memory": "64Mi"},
+				"monthlySavings": {"cpu": 1, "memory": 0.5},
+				"currentEfficiency": {"cpu": 0.1, "memory": 0.4}},
+			{"clusterID": "c", "namespace": "shop", "controllerKind": "deployment", "controllerName": "api",
+				"containerName": "server",
+				"recommendedRequest": {"cpu": "120m", "memory": "300Mi"},
+				"latestKnownRequest": {"cpu": "1", "memory": "1Gi"},
+				"monthlySavings": {"cpu": 18.5, "memory": 3.25},
+				"currentEfficiency": {"cpu": 0.08, "memory": 0.2}}
+		],
+		"TotalMonthlySavings": 23.25
+	}
+}`
+
+func newSizingTestServer(t *testing.T, cfg kubecost.Config, query *url.Values) *KubecostServer {
+	t.Helper()
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/model/savings/requestSizingV2" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		*query = r.URL.Query()
+		w.Write([]byte(sizingResponse))
+	}))
+	t.Cleanup(mock.Close)
+
+	cfg.BaseURL = mock.URL
+	client, err := kubecost.NewClient(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return NewKubecostServer(client)
+}
+
+func TestGetSizingRecommendations(t *testing.T) {
+	var query url.Values
+	server := newSizingTestServer(t, kubecost.Config{SizingWindow: "3d", SizingTargetUtilization: 0.65}, &query)
+
+	resp, err := server.GetSizingRecommendations(context.Background(), &SizingRecommendationQuery{
+		ResourceID: "controller/shop/api",
+	})
+	if err != nil {
+		t.Fatalf("GetSizingRecommendations failed: %v", err)
+	}
+	if query.Get("filter") != `controllerName:"api"+namespace:"shop"` || query.Get("window") != "3d" ||
+		query.Get("targetCPUUtilization") != "0.65" || query.Get("targetRAMUtilization") != "0.65" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if resp.MonthlySavings != 23.25 || resp.TargetUtilization != 0.65 || resp.Window != "3d" {
+		t.Errorf("Unexpected totals %+v", resp)
+	}
+	if len(resp.Containers) != 2 {
+		t.Fatalf("Expected two containers, got %d", len(resp.Containers))
+	}
+	c := resp.Containers[0]
+	if c.Container != "server" || c.MonthlySavings != 21.75 || c.CurrentCPURequest != "1" ||
+		c.RecommendedCPURequest != "120m" || c.RecommendedMemoryRequest != "300Mi" || c.CPUEfficiency != 0.08 {
+		t.Errorf("Expected the largest saving first, got %+v", c)
+	}
+}
+
+func TestGetSizingRecommendationsOverrides(t *testing.T) {
+	var query url.Values
+	server := newSizingTestServer(t, kubecost.Config{SizingWindow: "3d", SizingTargetUtilization: 0.65}, &query)
+
+	resp, err := server.GetSizingRecommendations(context.Background(), &SizingRecommendationQuery{
+		ResourceID: "namespace/shop", Window: "7d", TargetUtilization: 0.8,
+	})
+	if err != nil {
+		t.Fatalf("GetSizingRecommendations failed: %v", err)
+	}
+	if query.Get("filter") != `namespace:"shop"` || query.Get("window") != "7d" || query.Get("targetCPUUtilization") != "0.8" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if resp.TargetUtilization != 0.8 || resp.Window != "7d" {
+		t.Errorf("Expected the request's window and target, got %+v", resp)
+	}
+}
+
+func TestGetSizingRecommendationsRejectsOtherResources(t *testing.T) {
+	var query url.Values
+	server := newSizingTestServer(t, kubecost.Config{}, &query)
+
+	for _, id := range []string{"", "pod/shop/api-1", "node/n1"} {
+		_, err := server.GetSizingRecommendations(context.Background(), &SizingRecommendationQuery{ResourceID: id})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%q: expected InvalidArgument, got %v", id, err)
+		}
+	}
+	if query != nil {
+		t.Errorf("Expected no Kubecost query, got %v", query)
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/logging"
+)
+
+// storagePricingSpec returns the GiB-month price of a storage class from
+// Kubecost's disk assets over the default window. The descriptor names the
+// storage class as its SKU or with a "storage_class" tag, or names a claim with
+// "namespace" and "claim" tags to price the volume bound to it. Volumes of a
+// storage class are priced at their combined cost over their combined GiB-hours.
+// A claim's spec also reports how much of its volume Kubecost allocated to the
+// namespace's workloads.
+func storagePricingSpec(ctx context.Context, cli *kubecost.Client, r *ResourceDescriptor) (*PricingSpec, error) {
+	class := r.Tags["storage_class"]
+	if class == "" {
+		class = r.Sku
+	}
+	claim := resourceRef{kind: dimPVC, namespace: r.Tags["namespace"], name: r.Tags["claim"]}
+	if class == "" && (claim.namespace == "" || claim.name == "") {
+		return nil, status.Error(codes.InvalidArgument,
+			"k8s-pvc pricing needs a storage class SKU or namespace and claim tags")
+	}
+	window := windowFor(cli, "", "")
+	ctx = annotateRequest(ctx, "storage_class", class, "claim", claim.namespace+"/"+claim.name, "window", window)
+
+	q := kubecost.AssetsQuery{
+		Window:     window,
+		Types:      []string{kubecost.AssetDisk},
+		Accumulate: true,
+	}
+	if class == "" {
+		q.Filter = assetKinds[dimPVC].filter(claim)
+	}
+	resp, err := cli.Assets(ctx, q)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	var disks []kubecost.Asset
+	for _, set := range resp.Data {
+		for _, asset := range set {
+			if asset.Type != kubecost.AssetDisk {
+				continue
+			}
+			if class != "" && asset.StorageClass == class ||
+				class == "" && assetKinds[dimPVC].ref(asset) == claim {
+				disks = append(disks, asset)
+			}
+		}
+	}
+	if len(disks) == 0 {
+		what := "storage class " + class
+		if class == "" {
+			what = "claim " + claim.namespace + "/" + claim.name
+		}
+		return nil, status.Errorf(codes.NotFound, "no Kubecost disk assets match %s over %s", what, window)
+	}
+	slices.SortFunc(disks, func(a, b kubecost.Asset) int {
+		return strings.Compare(a.Properties.Cluster+"/"+a.Properties.Name, b.Properties.Cluster+"/"+b.Properties.Name)
+	})
+	spec := toStoragePricingSpec(r.ResourceType, window, disks)
+	if class == "" && len(disks) == 1 {
+		// The claim's workloads were charged for part of the volume only; the
+		// rest of its cost is idle
+		cost, gibHours, err := allocatedPVCost(ctx, cli, window, claim.namespace, disks[0])
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "kubecost volume allocation unavailable", "error", err)
+		} else {
+			spec.PluginMetadata["allocated_cost"] = formatRate(cost)
+			spec.PluginMetadata["allocated_gib_hours"] = formatRate(gibHours)
+		}
+	}
+	return spec, nil
+}
+
+// allocatedPVCost returns the cost and GiB-hours of disk that Kubecost
+// allocated to the workloads of namespace over window, from the per-volume
+// breakdown of the namespace's allocations.
+func allocatedPVCost(
+	ctx context.Context,
+	cli *kubecost.Client,
+	window, namespace string,
+	disk kubecost.Asset,
+) (cost, gibHours float64, err error) {
+	resp, err := cli.EnhancedAllocation(ctx, kubecost.AllocationQuery{
+		Window:      window,
+		Namespaces:  []string{namespace},
+		AggregateBy: []string{dimNamespace},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, item := range resp.Items {
+		for _, pv := range item.PVs {
+			if pv.Name == disk.Properties.Name && (pv.Cluster == "" || pv.Cluster == disk.Properties.Cluster) {
+				cost += pv.Cost
+				gibHours += pv.GiBHours
+			}
+		}
+	}
+	return cost, gibHours, nil
+}
+
+// toStoragePricingSpec prices disks at their combined cost per GiB-month.
+// Placement is taken from the first disk.
+func toStoragePricingSpec(resourceType, window string, disks []kubecost.Asset) *PricingSpec {
+	first := disks[0]
+	var sum kubecost.Asset
+	for _, d := range disks {
+		sum.TotalCost += d.TotalCost
+		sum.ByteHours += d.ByteHours
+		if d.ByteHours == 0 {
+			sum.ByteHours += d.Bytes * d.Hours()
+		}
+	}
+
+	metadata := map[string]string{
+		"source":  assetCostSource,
+		"window":  window,
+		"volumes": strconv.Itoa(len(disks)),
+	}
+	if len(disks) == 1 {
+		metadata["volume"] = first.Properties.Name
+		metadata["cluster"] = first.Properties.Cluster
+		metadata["size_gib"] = formatRate(first.GiB())
+		if first.ClaimName != "" {
+			metadata["claim"] = first.ClaimNS + "/" + first.ClaimName
+		}
+	}
+
+	return &PricingSpec{
+		Provider:       first.CloudProvider(),
+		ResourceType:   resourceType,
+		Sku:            first.StorageClass,
+		Region:         first.Region(),
+		BillingMode:    "per_gib_month",
+		RatePerUnit:    sum.GiBMonthlyRate(),
+		Currency:       "USD",
+		Description:    fmt.Sprintf("Kubecost GiB-month price for storage class %s", first.StorageClass),
+		PluginMetadata: metadata,
+	}
+}
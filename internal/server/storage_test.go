@@ -0,0 +1,210 @@
+package server //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// diskAssetsResponse holds two gp3 volumes, one bound to db/data, and an
+// unbound standard volume.
+const diskAssetsResponse = `{
+	"code": 200,
+	"data": [{
+		"a/pv-1": {"type": "Disk", "storageClass": "gp3", "claimNamespace": "db", "claimName": "data",
+			"start": "2024-01-01T00:00:00Z", "minutes": 1440, "bytes": 107374182400,
+			"byteHours": 2576980377600, "totalCost": 0.24,
+			"properties": {"cluster": "a", "name": "pv-1", "providerID": "aws:///us-east-1a/vol-1"}},
+		"a/pv-2": {"type": "Disk", "storageClass": "gp3", "claimNamespace": "web", "claimName": "cache",
+			"start": "2024-01-01T00:00:00Z", "minutes": 720, "bytes": 10737418240, "totalCost": 0.06,
+			"properties": {"cluster": "a", "name": "pv-2"}},
+		"a/pv-3": {"type": "Disk", "storageClass": "standard",
+			"start": "2024-01-01T00:00:00Z", "minutes": 1440, "bytes": 10737418240, "totalCost": 0.01,
+			"properties": {"cluster": "a", "name": "pv-3"}}
+	}]
+}`
+
+// dbAllocationResponse charges the db namespace for half of pv-1 and for a
+// volume in another cluster.
+const dbAllocationResponse = `{
+	"code": 200,
+	"data": [{
+		"db": {"name": "db", "start": "2024-01-01T00:00:00Z", "end": "2024-01-02T00:00:00Z", "pvCost": 0.15,
+			"pvs": {
+				"cluster=a:name=pv-1": {"byteHours": 1288490188800, "cost": 0.12},
+				"cluster=b:name=pv-1": {"byteHours": 1073741824, "cost": 0.03}
+			}}
+	}]
+}`
+
+// newStorageTestServer serves disk and node assets, recording the filter of
+// each assets query, and the db namespace's allocations.
+func newStorageTestServer(t *testing.T, queries *[]string) *KubecostServer {
+	t.Helper()
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/model/allocation" {
+			w.Write([]byte(dbAllocationResponse))
+			return
+		}
+		*queries = append(*queries, r.URL.Query().Get("filter"))
+		if strings.HasPrefix(r.URL.Query().Get("filter"), `assetType:"node"`) {
+			w.Write([]byte(nodeAssetsResponse))
+			return
+		}
+		w.Write([]byte(diskAssetsResponse))
+	}))
+	t.Cleanup(mock.Close)
+
+	client, err := kubecost.NewClient(context.Background(), kubecost.Config{BaseURL: mock.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return NewKubecostServer(client)
+}
+
+func TestGetActualCostPVCFromAssets(t *testing.T) {
+	var queries []string
+	server := newStorageTestServer(t, &queries)
+
+	resp, err := server.GetActualCost(context.Background(), &ActualCostQuery{ResourceID: "pvc/db/data"})
+	if err != nil {
+		t.Fatalf("GetActualCost failed: %v", err)
+	}
+	if len(queries) != 1 || queries[0] != `assetType:"disk"+claimName:"data"+claimNamespace:"db"` {
+		t.Errorf("Expected a single disk assets query filtered to the claim, got %v", queries)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("Expected one result, got %d", len(resp.Results))
+	}
+	r := resp.Results[0]
+	if r.Cost != 0.24 || r.UsageAmount != 2400 || r.UsageUnit != "GiB-hours" || r.Source != assetCostSource {
+		t.Errorf("Unexpected result %+v", r)
+	}
+}
+
+func TestBatchActualCostPVCsAndNodes(t *testing.T) {
+	var queries []string
+	server := newStorageTestServer(t, &queries)
+
+	resp, err := server.BatchActualCost(context.Background(), &BatchActualCostQuery{
+		ResourceIDs: []string{"pvc/web/cache", "node/n2", "pvc/db/data", "pvc/db"},
+	})
+	if err != nil {
+		t.Fatalf("BatchActualCost failed: %v", err)
+	}
+	if len(queries) != 2 || queries[0] != `assetType:"node"+name:"n2"` || queries[1] != `assetType:"disk"` {
+		t.Errorf("Expected one node and one disk assets query, got %v", queries)
+	}
+
+	want := []float64{0.06, 5, 0.24}
+	for i, cost := range want {
+		res := resp.Resources[i]
+		if len(res.Results) != 1 || res.Results[0].Cost != cost {
+			t.Errorf("%s: expected cost %v, got %+v", res.ResourceID, cost, res.Results)
+		}
+	}
+	if resp.Resources[3].Error == "" {
+		t.Errorf("Expected an error for a PVC ID without a claim name")
+	}
+}
+
+func TestGetPricingSpecPVC(t *testing.T) {
+	var queries []string
+	server := newStorageTestServer(t, &queries)
+
+	// pv-1 holds 2400 GiB-hours for 0.24 and pv-2 120 for 0.06.
+	tests := []struct {
+		name   string
+		desc   *ResourceDescriptor
+		rate   float64
+		volume string
+	}{
+		{"storage class sku", &ResourceDescriptor{Sku: "gp3"}, 0.30 / 2520 * 730, ""},
+		{"storage class tag", &ResourceDescriptor{Tags: map[string]string{"storage_class": "standard"}}, 0.01 / 240 * 730, "pv-3"},
+		{"claim", &ResourceDescriptor{Tags: map[string]string{"namespace": "db", "claim": "data"}}, 0.24 / 2400 * 730, "pv-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.desc.ResourceType = "k8s-pvc"
+			spec, err := server.GetPricingSpec(context.Background(), tt.desc)
+			if err != nil {
+				t.Fatalf("GetPricingSpec failed: %v", err)
+			}
+			if math.Abs(spec.RatePerUnit-tt.rate) > 1e-9 || spec.BillingMode != "per_gib_month" {
+				t.Errorf("Expected %v per GiB-month, got %v %s", tt.rate, spec.RatePerUnit, spec.BillingMode)
+			}
+			if spec.PluginMetadata["volume"] != tt.volume {
+				t.Errorf("Expected volume %q, got %v", tt.volume, spec.PluginMetadata)
+			}
+			if _, ok := spec.PluginMetadata["allocated_cost"]; ok != (tt.name == "claim") {
+				t.Errorf("Expected an allocated cost for claims only, got %v", spec.PluginMetadata)
+			}
+		})
+	}
+
+	if queries[0] != `assetType:"disk"` || queries[2] != `assetType:"disk"+claimName:"data"+claimNamespace:"db"` {
+		t.Errorf("Expected only the claim query to be filtered to the claim, got %v", queries)
+	}
+
+	spec, _ := server.GetPricingSpec(context.Background(), tests[2].desc)
+	if spec.Sku != "gp3" || spec.Provider != "aws" || spec.Region != "us-east-1" || spec.PluginMetadata["size_gib"] != "100" {
+		t.Errorf("Unexpected claim spec %+v", spec)
+	}
+	if spec.PluginMetadata["allocated_cost"] != "0.12" || spec.PluginMetadata["allocated_gib_hours"] != "1200" {
+		t.Errorf("Expected the claim's allocated share of pv-1, got %v", spec.PluginMetadata)
+	}
+
+	for desc, want := range map[*ResourceDescriptor]codes.Code{
+		{ResourceType: "k8s-pvc", Tags: map[string]string{"claim": "data"}}: codes.InvalidArgument,
+		{ResourceType: "k8s-pvc", Sku: "io2"}:                               codes.NotFound,
+	} {
+		if _, err := server.GetPricingSpec(context.Background(), desc); status.Code(err) != want {
+			t.Errorf("Expected %v for %+v, got %v", want, desc, err)
+		}
+	}
+}
+
+func TestGetProjectedCostPVC(t *testing.T) {
+	var queries []string
+	server := newStorageTestServer(t, &queries)
+
+	info, err := server.GetProjectedCost(context.Background(), &ResourceDescriptor{
+		ResourceType: "k8s-pvc",
+		Tags:         map[string]string{"namespace": "db", "claim": "data"},
+	})
+	if err != nil {
+		t.Fatalf("GetProjectedCost failed: %v", err)
+	}
+	if math.Abs(info.CostPerMonth-0.24*avgDaysForProjection) > 1e-9 {
+		t.Errorf("Expected a month of the daily cost, got %v", info.CostPerMonth)
+	}
+}
+
+func TestDescriptorResourceID(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		tags         map[string]string
+		want         string
+	}{
+		{"k8s-namespace", map[string]string{"namespace": "db"}, "namespace/db"},
+		{"k8s-pod", map[string]string{"namespace": "db", "pod": "db-0"}, "pod/db/db-0"},
+		{"k8s-controller", map[string]string{"namespace": "db", "controller": "db"}, "controller/db/db"},
+		{"k8s-node", map[string]string{"node": "n1"}, "node/n1"},
+		{"k8s-pvc", map[string]string{"namespace": "db", "claim": "data"}, "pvc/db/data"},
+		{"k8s-pvc", map[string]string{"claim": "data"}, ""},
+		{"k8s-namespace", nil, ""},
+	}
+	for _, tt := range tests {
+		got := descriptorResourceID(&ResourceDescriptor{ResourceType: tt.resourceType, Tags: tt.tags})
+		if got != tt.want {
+			t.Errorf("%s %v: expected %q, got %q", tt.resourceType, tt.tags, tt.want, got)
+		}
+	}
+}
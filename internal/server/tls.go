@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/rshade/pulumicost-plugin-kubecost/internal/filereload"
+	kubecost "github.com/rshade/pulumicost-plugin-kubecost/internal/kubecost"
+)
+
+// TLSConfig returns the TLS configuration for the plugin's gRPC server, or nil
+// when serverCertFile is not set and the server is plaintext. With
+// serverClientCaFile, clients must present a certificate signed by one of its
+// CAs. The certificate files are reloaded for new connections when they change
+// on disk, and a reload that fails keeps the previous configuration.
+func TLSConfig(cfg kubecost.Config) (*tls.Config, error) {
+	if cfg.ServerCertFile == "" {
+		return nil, nil //nolint:nilnil // no certificate means plaintext
+	}
+	var files []string
+	for _, p := range []string{cfg.ServerCertFile, cfg.ServerKeyFile, cfg.ServerClientCAFile} {
+		if p != "" {
+			files = append(files, p)
+		}
+	}
+	current, err := filereload.New(files, func() (*tls.Config, error) { return newServerTLSConfig(cfg) }, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) { return current.Get(), nil },
+	}, nil
+}
+
+func newServerTLSConfig(cfg kubecost.Config) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.ServerCertFile, cfg.ServerKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading server certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if cfg.ServerClientCAFile != "" {
+		pem, readErr := os.ReadFile(cfg.ServerClientCAFile)
+		if readErr != nil {
+			return nil, fmt.Errorf("reading serverClientCaFile: %w", readErr)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("serverClientCaFile %s contains no PEM certificates", cfg.ServerClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsCfg, nil
+}
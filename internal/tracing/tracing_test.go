@@ -0,0 +1,76 @@
+package tracing //nolint:testpackage // Package name intentionally matches implementation for simplicity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// recordSpans installs a tracer provider recording spans for the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return rec
+}
+
+func TestStartRPCContinuesIncomingTrace(t *testing.T) {
+	if _, err := Setup(context.Background(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	rec := recordSpans(t)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01"))
+	_, span := StartRPC(ctx, "GetActualCost")
+	End(span, errors.New("kubecost unavailable"))
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	got := spans[0]
+	if got.SpanContext().TraceID().String() != traceID {
+		t.Errorf("Expected trace %s from metadata, got %s", traceID, got.SpanContext().TraceID())
+	}
+	if got.Name() != "CostSource/GetActualCost" || got.SpanKind() != trace.SpanKindServer {
+		t.Errorf("Unexpected span %s (%v)", got.Name(), got.SpanKind())
+	}
+	if got.Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", got.Status())
+	}
+}
+
+func TestSetupExporters(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterStdout, Stdout: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := Tracer().Start(context.Background(), "test")
+	span.End()
+	if err = shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"Name":"test"`)) {
+		t.Errorf("Expected the span on the stdout exporter, got %s", buf.String())
+	}
+
+	if _, err = Setup(context.Background(), Options{Exporter: "zipkin"}); err == nil {
+		t.Error("Expected an error for an unknown exporter")
+	}
+}